@@ -24,6 +24,10 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // CertificateRequestSpec defines the desired state of CertificateRequest.
+// +kubebuilder:validation:XValidation:rule="!has(self.issuerProfile) || (!has(self.issuerName) && !has(self.issuerKind))",message="issuerProfile cannot be combined with issuerName or issuerKind"
+// +kubebuilder:validation:XValidation:rule="!has(self.backend) || self.backend == 'CertManager' || (!has(self.issuerProfile) && !has(self.issuerName) && !has(self.issuerKind))",message="issuer fields only apply to the CertManager backend"
+// +kubebuilder:validation:XValidation:rule="!has(self.vaultPKI) || !has(self.backend) || self.backend == 'VaultPKI'",message="vaultPKI only applies to the VaultPKI backend"
+// +kubebuilder:validation:XValidation:rule="!has(self.vaultPKI) || (has(self.vaultConnection) && self.vaultConnection.kind == 'VaultConnection')",message="vaultPKI requires a VaultConnection in the request's namespace"
 type CertificateRequestSpec struct {
 	// The name of the Kubernetes secret to store the generated certificate
 	// +kubebuilder:validation:Required
@@ -42,20 +46,239 @@ type CertificateRequestSpec struct {
 	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	Subdomain string `json:"subdomain,omitempty"`
 
-	// Vault path (e.g. kv/data/cert-info) to read additional metadata (optional)
+	// Vault path (e.g. kv/domains) to read the domain from (optional).
+	// KV v1 and v2 mounts are detected; the data/ segment of KV v2 paths may be omitted.
 	// +kubebuilder:default="kv/data/domains"
 	VaultPath string `json:"vaultPath,omitempty"`
 
+	// DomainSource selects where domainKey is looked up.
+	// Defaults to the source configured on the operator (Vault unless changed).
+	// +kubebuilder:validation:Optional
+	DomainSource *DomainSourceRef `json:"domainSource,omitempty"`
+
+	// VaultConnection selects the Vault server used for Vault domain lookups and the VaultPKI backend.
+	// Defaults to the Vault configured in the operator's environment.
+	// +kubebuilder:validation:Optional
+	VaultConnection *VaultConnectionReference `json:"vaultConnection,omitempty"`
+
+	// Backend issues the certificate: CertManager creates a cert-manager Certificate,
+	// InternalCA signs it with the operator's CA and VaultPKI issues it from a Vault PKI
+	// secrets engine; both write the TLS Secret directly.
+	// Defaults to the backend configured on the operator (CertManager unless changed)
+	// +kubebuilder:validation:Optional
+	Backend CertificateBackend `json:"backend,omitempty"`
+
+	// VaultPKI selects the PKI mount and role for the VaultPKI backend, read through vaultConnection.
+	// Only allowed with a VaultConnection in the request's namespace; the operator's connections
+	// always use the mount and role configured on the operator
+	// +kubebuilder:validation:Optional
+	VaultPKI *VaultPKIReference `json:"vaultPKI,omitempty"`
+
+	// IssuerProfile selects the ClusterIssuer generated from an IssuerProfile.
+	// Cannot be combined with issuerName or issuerKind
+	// +kubebuilder:validation:Optional
+	IssuerProfile string `json:"issuerProfile,omitempty"`
+
 	// IssuerRef is a reference to the issuer for this certificate.
-	// If not specified, defaults to 'ca-issuer' ClusterIssuer
+	// If not specified, the domain entry's issuer is used, then 'ca-issuer'
 	// +kubebuilder:validation:Optional
-	// +kubebuilder:default="ca-issuer"
 	IssuerName string `json:"issuerName,omitempty"`
 
-	// IssuerKind is the kind of the issuer (Issuer or ClusterIssuer)
+	// IssuerKind is the kind of the issuer (Issuer or ClusterIssuer).
+	// If not specified, the domain entry's issuer kind is used, then ClusterIssuer
+	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
-	// +kubebuilder:default="ClusterIssuer"
 	IssuerKind string `json:"issuerKind,omitempty"`
+
+	// DNSNames adds subject alternative names to the certificate, each built from a
+	// domain key looked up in the same domain source as domainKey (optional)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	// +listType=atomic
+	DNSNames []CertificateDNSName `json:"dnsNames,omitempty"`
+
+	// PrivateKey configures the certificate's private key.
+	// If not specified, cert-manager's default (RSA 2048, PKCS1) is used
+	// +kubebuilder:validation:Optional
+	PrivateKey *CertificatePrivateKey `json:"privateKey,omitempty"`
+
+	// Duration is the requested lifetime of the certificate (at least 1h).
+	// If not specified, the issuer's default is used (90 days for cert-manager)
+	// +kubebuilder:validation:Optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before expiry the certificate is renewed (at least 5m, less than duration).
+	// If not specified, cert-manager renews after two thirds of the lifetime
+	// +kubebuilder:validation:Optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// Usages are the key usages requested for the certificate.
+	// If not specified, cert-manager requests "digital signature" and "key encipherment"
+	// +kubebuilder:validation:Optional
+	// +listType=set
+	Usages []KeyUsage `json:"usages,omitempty"`
+
+	// AdditionalOutputFormats adds extra encodings of the key and certificate to the Secret
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	AdditionalOutputFormats []CertificateAdditionalOutputFormat `json:"additionalOutputFormats,omitempty"`
+
+	// Subject sets the certificate subject. Values are Go templates that can use
+	// .Name, .Namespace, .FQDN, .Domain, .Subdomain and .Values from the domain entry.
+	// If not specified, the organization is the domain and the organizational unit the namespace
+	// +kubebuilder:validation:Optional
+	Subject *CertificateSubject `json:"subject,omitempty"`
+
+	// SecretTemplate sets labels and annotations on the TLS Secret.
+	// cert-manager keeps the Secret in sync as the template changes
+	// +kubebuilder:validation:Optional
+	SecretTemplate *SecretTemplate `json:"secretTemplate,omitempty"`
+
+	// ReplicateTo copies the issued TLS Secret into other namespaces and keeps the copies
+	// updated on renewal. Copies are removed when the request is deleted. A target namespace
+	// must list the request's namespace in its networking.alm.homelab/replicate-from annotation
+	// +kubebuilder:validation:Optional
+	ReplicateTo *SecretReplication `json:"replicateTo,omitempty"`
+
+	// DeletionPolicy controls whether the TLS Secret is kept (Retain) or removed (Delete)
+	// when the CertificateRequest is deleted
+	// +kubebuilder:default=Retain
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// CertificateSubject holds templated X.509 subject fields; values that render empty are dropped
+type CertificateSubject struct {
+	// Organizations of the subject
+	// +kubebuilder:validation:Optional
+	Organizations []string `json:"organizations,omitempty"`
+
+	// Organizational units of the subject
+	// +kubebuilder:validation:Optional
+	OrganizationalUnits []string `json:"organizationalUnits,omitempty"`
+
+	// Countries of the subject
+	// +kubebuilder:validation:Optional
+	Countries []string `json:"countries,omitempty"`
+
+	// Provinces of the subject
+	// +kubebuilder:validation:Optional
+	Provinces []string `json:"provinces,omitempty"`
+
+	// Localities of the subject
+	// +kubebuilder:validation:Optional
+	Localities []string `json:"localities,omitempty"`
+
+	// Serial number of the subject
+	// +kubebuilder:validation:Optional
+	SerialNumber string `json:"serialNumber,omitempty"`
+}
+
+// SecretTemplate holds metadata copied onto a generated Secret
+type SecretTemplate struct {
+	// Labels to set on the Secret
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to set on the Secret; cert-manager.io/ annotations are reserved
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// SecretReplication selects the namespaces a TLS Secret is copied to; both fields may be combined
+// +kubebuilder:validation:XValidation:rule="has(self.namespaces) || has(self.namespaceSelector)",message="namespaces or namespaceSelector must be set"
+type SecretReplication struct {
+	// Namespaces to copy the Secret to
+	// +kubebuilder:validation:Optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects namespaces to copy the Secret to by label
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// CertificateBackend selects what issues a request's certificate
+// +kubebuilder:validation:Enum=CertManager;InternalCA;VaultPKI
+type CertificateBackend string
+
+const (
+	// CertificateBackendCertManager issues through a cert-manager Certificate
+	CertificateBackendCertManager CertificateBackend = "CertManager"
+	// CertificateBackendInternalCA signs with the operator's own CA
+	CertificateBackendInternalCA CertificateBackend = "InternalCA"
+	// CertificateBackendVaultPKI issues from a Vault PKI secrets engine
+	CertificateBackendVaultPKI CertificateBackend = "VaultPKI"
+)
+
+// VaultPKIReference selects a Vault PKI secrets engine role
+type VaultPKIReference struct {
+	// Mount path of the PKI secrets engine (e.g. pki)
+	// +kubebuilder:validation:Optional
+	Mount string `json:"mount,omitempty"`
+
+	// Role issued from (pki/issue/<role>)
+	// +kubebuilder:validation:Optional
+	Role string `json:"role,omitempty"`
+}
+
+// DeletionPolicy controls what happens to a request's TLS Secret when the request is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain keeps the TLS Secret
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDelete removes the TLS Secret
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+)
+
+// CertificatePrivateKey configures the private key of a certificate
+type CertificatePrivateKey struct {
+	// Algorithm of the key
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=RSA;ECDSA;Ed25519
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Size of the key in bits: 2048 to 8192 for RSA, 256, 384 or 521 for ECDSA, unset for Ed25519
+	// +kubebuilder:validation:Optional
+	Size int `json:"size,omitempty"`
+
+	// RotationPolicy controls whether a new key is generated on renewal
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Never;Always
+	RotationPolicy string `json:"rotationPolicy,omitempty"`
+
+	// Encoding of the key stored in the Secret
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=PKCS1;PKCS8
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// KeyUsage is a cert-manager key usage such as "server auth" or "digital signature"
+// +kubebuilder:validation:Enum="signing";"digital signature";"content commitment";"key encipherment";"key agreement";"data encipherment";"cert sign";"crl sign";"encipher only";"decipher only";"any";"server auth";"client auth";"code signing";"email protection";"s/mime";"ipsec end system";"ipsec tunnel";"ipsec user";"timestamping";"ocsp signing";"microsoft sgc";"netscape sgc"
+type KeyUsage string
+
+// CertificateAdditionalOutputFormat adds an extra encoding to the certificate's Secret
+type CertificateAdditionalOutputFormat struct {
+	// Type of the output: DER adds key.der, CombinedPEM adds tls-combined.pem
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=DER;CombinedPEM
+	Type string `json:"type"`
+}
+
+// CertificateDNSName is a subject alternative name built from a domain key
+type CertificateDNSName struct {
+	// The key used to fetch the domain, defaults to spec.domainKey
+	// +kubebuilder:validation:Optional
+	DomainKey string `json:"domainKey,omitempty"`
+
+	// The subdomain to prepend to the domain: a name such as "api" or "api.v2",
+	// a wildcard such as "*" or "*.apps", or empty for the domain itself
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(\*|(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*)?$`
+	Subdomain string `json:"subdomain,omitempty"`
 }
 
 // CertificateRequestStatus defines the observed state of CertificateRequest.
@@ -63,12 +286,53 @@ type CertificateRequestStatus struct {
 	// The computed fully qualified domain name (FQDN)
 	FQDN string `json:"fqdn,omitempty"`
 
-	// True if the Certificate has been successfully created
+	// All DNS names on the certificate, starting with the FQDN
+	// +kubebuilder:validation:Optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// The backend that issued the certificate
+	// +kubebuilder:validation:Optional
+	Backend CertificateBackend `json:"backend,omitempty"`
+
+	// The issuer the Certificate is requested from, as Kind/name
+	// +kubebuilder:validation:Optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Namespaces the TLS Secret is currently copied to
+	// +kubebuilder:validation:Optional
+	ReplicatedNamespaces []string `json:"replicatedNamespaces,omitempty"`
+
+	// True if the certificate has been issued; mirrors the Ready condition
 	Ready bool `json:"ready,omitempty"`
+
+	// The generation of the spec the status was computed from
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Expiry of the issued certificate
+	// +kubebuilder:validation:Optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// When the certificate will be renewed
+	// +kubebuilder:validation:Optional
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
+
+	// Conditions describe the state of the request (Ready, VaultResolved, IssuerReady, CertificateIssued, SecretReplicated)
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="FQDN",type=string,JSONPath=`.status.fqdn`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Not After",type=date,JSONPath=`.status.notAfter`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // CertificateRequest is the Schema for the certificaterequests API.
 type CertificateRequest struct {
@@ -42,6 +42,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CertificateRequestList{},
 		&IngressRequest{},
 		&IngressRequestList{},
+		&VaultConnection{},
+		&VaultConnectionList{},
+		&ClusterVaultConnection{},
+		&ClusterVaultConnectionList{},
+		&IssuerProfile{},
+		&IssuerProfileList{},
 	)
 	metav1.AddToGroupVersion(scheme, GroupVersion)
 	return nil
@@ -24,51 +24,249 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // IngressRequestSpec defines the desired state of IngressRequest.
+// +kubebuilder:validation:XValidation:rule="has(self.serviceName) || has(self.backends) || (has(self.routes) && size(self.routes) > 0 && self.routes.all(r, has(r.serviceName)))",message="serviceName or backends is required unless every route sets a serviceName"
+// +kubebuilder:validation:XValidation:rule="!has(self.backends) || (!has(self.serviceName) && !has(self.servicePort))",message="backends cannot be combined with serviceName or servicePort"
+// +kubebuilder:validation:XValidation:rule="!has(self.mirror) || has(self.serviceName) || has(self.backends)",message="mirror requires serviceName or backends"
+// +kubebuilder:validation:XValidation:rule="!has(self.protocol) || self.protocol == 'HTTP' || (!has(self.routes) && !has(self.mirror) && !has(self.middlewares))",message="routes, mirror and middlewares are only supported with the HTTP protocol"
+// +kubebuilder:validation:XValidation:rule="!has(self.protocol) || self.protocol == 'HTTP' || (has(self.entrypoints) && size(self.entrypoints) > 0)",message="entrypoints are required with the TCP and UDP protocols"
+// +kubebuilder:validation:XValidation:rule="!has(self.tls) || !has(self.protocol) || self.protocol != 'UDP'",message="tls is not supported with the UDP protocol"
+// +kubebuilder:validation:XValidation:rule="!has(self.tls) || !has(self.tls.passthrough) || !self.tls.passthrough || (has(self.protocol) && self.protocol == 'TCP')",message="tls.passthrough requires the TCP protocol"
 type IngressRequestSpec struct {
-	// Vault path to read domain configuration from
+	// Vault path to read domain configuration from.
+	// KV v1 and v2 mounts are detected; the data/ segment of KV v2 paths may be omitted.
 	// +kubebuilder:default="kv/data/domains"
 	VaultPath string `json:"vaultPath,omitempty"`
 
+	// Protocol of the traffic to route: HTTP generates an IngressRoute, TCP an IngressRouteTCP
+	// matching the FQDN by SNI and UDP an IngressRouteUDP forwarding everything on its entrypoints.
+	// TCP and UDP require entrypoints and do not support routes, mirror or middlewares.
+	// +kubebuilder:default=HTTP
+	// +kubebuilder:validation:Optional
+	Protocol IngressProtocol `json:"protocol,omitempty"`
+
 	// The subdomain to prepend to the domain
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	Subdomain string `json:"subdomain"`
 
-	// The name of the Kubernetes service to route traffic to
-	// +kubebuilder:validation:Required
+	// The name of the Kubernetes service to route traffic to.
+	// Required unless every route sets its own.
+	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:MinLength=1
-	ServiceName string `json:"serviceName"`
+	ServiceName string `json:"serviceName,omitempty"`
 
-	// The port of the service (can be port number or name)
-	// +kubebuilder:validation:Required
-	ServicePort string `json:"servicePort"`
+	// The port of the service, as a port number or name.
+	// May be omitted when the Service has exactly one port.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^([1-9][0-9]{0,4}|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	ServicePort string `json:"servicePort,omitempty"`
+
+	// Backends split the traffic between Services by weight through a TraefikService generated
+	// by the operator (weighted round robin). Replaces serviceName and servicePort.
+	// With TCP and UDP they are the weighted services of the generated route instead.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=atomic
+	Backends []WeightedBackend `json:"backends,omitempty"`
+
+	// Mirror copies a percentage of the requests sent to serviceName or backends to another Service,
+	// through a TraefikService generated by the operator
+	// +kubebuilder:validation:Optional
+	Mirror *MirrorBackend `json:"mirror,omitempty"`
+
+	// Routes split the traffic to the FQDN by path, headers or method, each to its own service.
+	// When empty, one route sends all traffic to serviceName.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=50
+	// +listType=atomic
+	Routes []IngressRequestRoute `json:"routes,omitempty"`
 
 	// The key used to fetch the domain from Vault
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
 	DomainKey string `json:"domainKey"`
 
-	// Traefik entrypoints to use (defaults to ["web"])
+	// DomainSource selects where domainKey is looked up.
+	// Defaults to the source configured on the operator (Vault unless changed).
+	// +kubebuilder:validation:Optional
+	DomainSource *DomainSourceRef `json:"domainSource,omitempty"`
+
+	// VaultConnection selects the Vault server used for Vault domain lookups.
+	// Defaults to the Vault configured in the operator's environment.
+	// +kubebuilder:validation:Optional
+	VaultConnection *VaultConnectionReference `json:"vaultConnection,omitempty"`
+
+	// Traefik entrypoints to use (defaults to the domain entry's entrypoints, then ["web"]).
+	// Required for TCP and UDP, whose entrypoints are usually dedicated ports.
 	// +kubebuilder:validation:Optional
 	Entrypoints []string `json:"entrypoints,omitempty"`
 
-	// TLS configuration for the ingress
+	// TLS configuration for the ingress. With TCP, TLS is terminated (or passed through) only when set;
+	// the domain entry's cert resolver applies to HTTP only.
 	// +kubebuilder:validation:Optional
 	TLS *IngressTLSConfig `json:"tls,omitempty"`
 
-	// Middlewares to apply to the route
+	// Middlewares to apply to the route (defaults to the domain entry's middlewares)
+	// +kubebuilder:validation:Optional
+	Middlewares []MiddlewareRef `json:"middlewares,omitempty"`
+}
+
+// IngressProtocol selects the kind of Traefik route generated for a request
+// +kubebuilder:validation:Enum=HTTP;TCP;UDP
+type IngressProtocol string
+
+const (
+	// IngressProtocolHTTP routes HTTP requests through an IngressRoute
+	IngressProtocolHTTP IngressProtocol = "HTTP"
+	// IngressProtocolTCP routes TCP connections through an IngressRouteTCP
+	IngressProtocolTCP IngressProtocol = "TCP"
+	// IngressProtocolUDP routes UDP datagrams through an IngressRouteUDP
+	IngressProtocolUDP IngressProtocol = "UDP"
+)
+
+// IngressRequestRoute sends the requests to the FQDN matching its path, headers and methods to a service
+// +kubebuilder:validation:XValidation:rule="!(has(self.path) && has(self.pathPrefix))",message="path and pathPrefix are mutually exclusive"
+type IngressRequestRoute struct {
+	// PathPrefix matches request paths starting with this prefix
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern="^/[^`]*$"
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
+	// Path matches this exact request path
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern="^/[^`]*$"
+	Path string `json:"path,omitempty"`
+
+	// Headers the request must carry, all with the given values
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	Headers []HeaderMatch `json:"headers,omitempty"`
+
+	// Methods matches requests using any of these HTTP methods
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Enum=GET;HEAD;POST;PUT;PATCH;DELETE;OPTIONS;CONNECT;TRACE
+	// +listType=set
+	Methods []string `json:"methods,omitempty"`
+
+	// Priority of the route; Traefik orders routes by rule length when zero
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Priority int `json:"priority,omitempty"`
+
+	// The Kubernetes service to route to (defaults to spec.serviceName, or spec.backends and spec.mirror)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// The port of the service, as a port number or name (defaults to spec.servicePort)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^([1-9][0-9]{0,4}|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	ServicePort string `json:"servicePort,omitempty"`
+
+	// Middlewares to apply to the route (defaults to spec.middlewares)
 	// +kubebuilder:validation:Optional
 	Middlewares []MiddlewareRef `json:"middlewares,omitempty"`
 }
 
+// WeightedBackend is a Service receiving a share of the traffic proportional to its weight
+type WeightedBackend struct {
+	// The name of the Kubernetes service
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName"`
+
+	// The port of the service, as a port number or name.
+	// May be omitted when the Service has exactly one port.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^([1-9][0-9]{0,4}|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	ServicePort string `json:"servicePort,omitempty"`
+
+	// Weight relative to the other backends; 0 sends the backend no traffic
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	Weight *int `json:"weight,omitempty"`
+}
+
+// MirrorBackend is a Service receiving copies of requests; its responses are discarded
+type MirrorBackend struct {
+	// The name of the Kubernetes service
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName"`
+
+	// The port of the service, as a port number or name.
+	// May be omitted when the Service has exactly one port.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^([1-9][0-9]{0,4}|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	ServicePort string `json:"servicePort,omitempty"`
+
+	// Percent of the requests copied to the mirror
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=100
+	Percent *int `json:"percent,omitempty"`
+}
+
+// HeaderMatch matches a request header value
+type HeaderMatch struct {
+	// Name of the header
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	Name string `json:"name"`
+
+	// Value the header must have
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^[^`]*$"
+	Value string `json:"value"`
+}
+
+// IngressTLSConfig configures how the route terminates TLS
+// +kubebuilder:validation:XValidation:rule="!has(self.autoCertificate) || !self.autoCertificate || (!has(self.certResolver) && !(has(self.passthrough) && self.passthrough))",message="autoCertificate cannot be combined with certResolver or passthrough"
+// +kubebuilder:validation:XValidation:rule="(has(self.autoCertificate) && self.autoCertificate) || (!has(self.issuerName) && !has(self.issuerKind) && !has(self.issuerProfile))",message="issuerName, issuerKind and issuerProfile require autoCertificate"
+// +kubebuilder:validation:XValidation:rule="!has(self.issuerProfile) || (!has(self.issuerName) && !has(self.issuerKind))",message="issuerProfile cannot be combined with issuerName or issuerKind"
 type IngressTLSConfig struct {
-	// Reference to TLS secret containing the certificate
+	// Reference to TLS secret containing the certificate.
+	// With autoCertificate, the Secret the certificate is issued to (defaults to <name>-tls)
 	// +kubebuilder:validation:Optional
 	SecretName string `json:"secretName,omitempty"`
 
 	// CertResolver for dynamic certificates (e.g. Let's Encrypt via Traefik)
 	// +kubebuilder:validation:Optional
 	CertResolver string `json:"certResolver,omitempty"`
+
+	// Passthrough forwards TCP connections still encrypted, leaving TLS to the Service.
+	// Only valid with the TCP protocol; secretName and certResolver are ignored.
+	// +kubebuilder:validation:Optional
+	Passthrough bool `json:"passthrough,omitempty"`
+
+	// AutoCertificate has the operator create a cert-manager Certificate <name>-tls for the FQDN,
+	// written to secretName or the Secret <name>-tls. The route uses it once the Secret is issued.
+	// +kubebuilder:validation:Optional
+	AutoCertificate bool `json:"autoCertificate,omitempty"`
+
+	// IssuerName of the automatic certificate.
+	// If not specified, the domain entry's issuer is used, then 'ca-issuer'
+	// +kubebuilder:validation:Optional
+	IssuerName string `json:"issuerName,omitempty"`
+
+	// IssuerKind of the automatic certificate (Issuer or ClusterIssuer).
+	// If not specified, the domain entry's issuer kind is used, then ClusterIssuer
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	IssuerKind string `json:"issuerKind,omitempty"`
+
+	// IssuerProfile selects the ClusterIssuer generated from an IssuerProfile for the automatic certificate.
+	// Cannot be combined with issuerName or issuerKind
+	// +kubebuilder:validation:Optional
+	IssuerProfile string `json:"issuerProfile,omitempty"`
 }
 
 type MiddlewareRef struct {
@@ -88,10 +286,29 @@ type IngressRequestStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	FQDN string `json:"fqdn,omitempty"`
+
+	// The generation of the spec the status was computed from
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the state of the request (Ready, VaultResolved, ServiceResolved, MiddlewaresResolved,
+	// and CertificateIssued with tls.autoCertificate)
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="FQDN",type=string,JSONPath=`.status.fqdn`
+// +kubebuilder:printcolumn:name="Service",type=string,JSONPath=`.spec.serviceName`
+// +kubebuilder:printcolumn:name="Protocol",type=string,JSONPath=`.spec.protocol`,priority=1
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // IngressRequest is the Schema for the ingressrequests API.
 type IngressRequest struct {
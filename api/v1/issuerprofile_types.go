@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IssuerProfileSpec describes the cert-manager ClusterIssuer the operator generates.
+// +kubebuilder:validation:XValidation:rule="has(self.acme) != has(self.ca)",message="exactly one of acme or ca must be set"
+type IssuerProfileSpec struct {
+	// ACME issues certificates from an ACME server such as Let's Encrypt
+	// +kubebuilder:validation:Optional
+	ACME *ACMEIssuerProfile `json:"acme,omitempty"`
+
+	// CA signs certificates with a CA key pair
+	// +kubebuilder:validation:Optional
+	CA *CAIssuerProfile `json:"ca,omitempty"`
+}
+
+// ACMEIssuerProfile configures an ACME ClusterIssuer solving DNS01 challenges
+type ACMEIssuerProfile struct {
+	// Environment selects the Let's Encrypt directory (Staging or Production)
+	// +kubebuilder:default=Production
+	// +kubebuilder:validation:Enum=Staging;Production
+	Environment string `json:"environment,omitempty"`
+
+	// Server overrides the ACME directory URL selected by environment
+	// +kubebuilder:validation:Optional
+	Server string `json:"server,omitempty"`
+
+	// Email registered with the ACME account
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Email string `json:"email"`
+
+	// DNS01 configures the DNS01 challenge solver
+	// +kubebuilder:validation:Required
+	DNS01 DNS01SolverProfile `json:"dns01"`
+}
+
+// DNS01SolverProfile configures a DNS01 provider whose API token is kept in Vault
+type DNS01SolverProfile struct {
+	// Provider is the DNS provider (Cloudflare or DigitalOcean)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Cloudflare;DigitalOcean
+	Provider string `json:"provider"`
+
+	// Credentials locates the provider API token in Vault. It is copied into the
+	// Secret the solver references and refreshed periodically.
+	// +kubebuilder:validation:Required
+	Credentials VaultCredentials `json:"credentials"`
+}
+
+// VaultCredentials locates a credential stored in a Vault KV secret
+// +kubebuilder:validation:XValidation:rule="!has(self.vaultConnection) || self.vaultConnection.kind == 'ClusterVaultConnection'",message="only a ClusterVaultConnection can be referenced"
+type VaultCredentials struct {
+	// VaultConnection selects the Vault server; only ClusterVaultConnection can be used.
+	// Defaults to the Vault configured in the operator's environment.
+	// +kubebuilder:validation:Optional
+	VaultConnection *VaultConnectionReference `json:"vaultConnection,omitempty"`
+
+	// Vault path of the KV secret (e.g. kv/dns/cloudflare)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Key within the KV secret
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// CAIssuerProfile configures a CA ClusterIssuer
+type CAIssuerProfile struct {
+	// Name of the Secret holding the CA key pair, in cert-manager's cluster resource namespace
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// IssuerProfileStatus defines the observed state of IssuerProfile.
+type IssuerProfileStatus struct {
+	// Name of the generated ClusterIssuer
+	// +kubebuilder:validation:Optional
+	IssuerName string `json:"issuerName,omitempty"`
+
+	// The generation of the spec the status was computed from
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the state of the profile (Ready, CredentialsSynced)
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Issuer",type=string,JSONPath=`.status.issuerName`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// IssuerProfile is the Schema for the issuerprofiles API.
+// The operator generates a cert-manager ClusterIssuer of the same name from it.
+type IssuerProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IssuerProfileSpec   `json:"spec,omitempty"`
+	Status IssuerProfileStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IssuerProfileList contains a list of IssuerProfile.
+type IssuerProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IssuerProfile `json:"items"`
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Condition types reported by requests
+const (
+	// ConditionReady is True when everything the request asked for is in place
+	ConditionReady = "Ready"
+	// ConditionVaultResolved is True when the request's domain key was resolved
+	ConditionVaultResolved = "VaultResolved"
+	// ConditionIssuerReady is True when the referenced Issuer or ClusterIssuer exists and is Ready
+	ConditionIssuerReady = "IssuerReady"
+	// ConditionCertificateIssued mirrors the Ready condition of the owned cert-manager Certificate
+	ConditionCertificateIssued = "CertificateIssued"
+	// ConditionServiceResolved is True when the target Service exists and exposes the requested port
+	ConditionServiceResolved = "ServiceResolved"
+	// ConditionMiddlewaresResolved is True when every referenced Traefik Middleware exists
+	ConditionMiddlewaresResolved = "MiddlewaresResolved"
+	// ConditionCredentialsSynced is True when an IssuerProfile's solver credentials were copied from Vault
+	ConditionCredentialsSynced = "CredentialsSynced"
+	// ConditionSecretReplicated is True when the TLS Secret was copied to every namespace selected by replicateTo
+	ConditionSecretReplicated = "SecretReplicated"
+)
+
+// Condition reasons reported by requests
+const (
+	ReasonDomainResolved           = "DomainResolved"
+	ReasonDomainLookupFailed       = "DomainLookupFailed"
+	ReasonCertificateSyncFailed    = "CertificateSyncFailed"
+	ReasonIssued                   = "Issued"
+	ReasonIssuing                  = "Issuing"
+	ReasonIssuanceFailed           = "IssuanceFailed"
+	ReasonIngressRouteSyncFailed   = "IngressRouteSyncFailed"
+	ReasonTraefikServiceConflict   = "TraefikServiceConflict"
+	ReasonTraefikServiceSyncFailed = "TraefikServiceSyncFailed"
+	ReasonServiceFound             = "ServiceFound"
+	ReasonServiceNotFound          = "ServiceNotFound"
+	ReasonServicePortNotFound      = "ServicePortNotFound"
+	ReasonServicePortRequired      = "ServicePortRequired"
+	ReasonMiddlewaresFound         = "MiddlewaresFound"
+	ReasonMiddlewareNotFound       = "MiddlewareNotFound"
+	ReasonRouteReady               = "RouteReady"
+	ReasonInvalidSpec              = "InvalidSpec"
+	ReasonIssuerReady              = "IssuerReady"
+	ReasonIssuerNotReady           = "IssuerNotReady"
+	ReasonIssuerConflict           = "IssuerConflict"
+	ReasonIssuerSyncFailed         = "IssuerSyncFailed"
+	ReasonCredentialsSynced        = "CredentialsSynced"
+	ReasonCredentialsSyncFailed    = "CredentialsSyncFailed"
+	ReasonReplicated               = "Replicated"
+	ReasonReplicationFailed        = "ReplicationFailed"
+	ReasonWaitingForSecret         = "WaitingForSecret"
+	ReasonCertificateConflict      = "CertificateConflict"
+)
+
+// DomainSourceRef selects where the domain for a request is looked up.
+// +kubebuilder:validation:XValidation:rule="self.kind != 'File' || !has(self.name)",message="name cannot be set for File sources"
+type DomainSourceRef struct {
+	// Kind of the domain source (Vault, ConfigMap, Secret or File)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Vault;ConfigMap;Secret;File
+	Kind string `json:"kind"`
+
+	// Name of the ConfigMap or Secret in the request's namespace holding the domains.
+	// A Secret must be annotated networking.alm.homelab/domain-source=true.
+	// Defaults to the source configured on the operator.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	Name string `json:"name,omitempty"`
+}
+
+// VaultConnectionReference names the Vault connection a request reads its domain from.
+type VaultConnectionReference struct {
+	// Kind of the connection (VaultConnection or ClusterVaultConnection)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=VaultConnection;ClusterVaultConnection
+	// +kubebuilder:default="VaultConnection"
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the connection; a VaultConnection must be in the request's namespace
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VaultConnectionSpec defines how the operator connects and logs in to a Vault server.
+type VaultConnectionSpec struct {
+	// Address of the Vault server (e.g. https://vault.example.com:8200)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+
+	// PEM encoded CA bundle used to verify the Vault server certificate
+	// +kubebuilder:validation:Optional
+	CABundle string `json:"caBundle,omitempty"`
+
+	// Vault Enterprise or OpenBao namespace
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Auth configures how the operator logs in
+	// +kubebuilder:validation:Required
+	Auth VaultAuth `json:"auth"`
+}
+
+// VaultAuth selects the Vault auth method and its parameters.
+// +kubebuilder:validation:XValidation:rule="self.method != 'token' || has(self.tokenSecretRef)",message="tokenSecretRef is required for token auth"
+// +kubebuilder:validation:XValidation:rule="self.method != 'kubernetes' || has(self.kubernetes)",message="kubernetes is required for kubernetes auth"
+// +kubebuilder:validation:XValidation:rule="self.method != 'approle' || has(self.appRole)",message="appRole is required for approle auth"
+type VaultAuth struct {
+	// Method is the auth method (token, kubernetes or approle)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=token;kubernetes;approle
+	Method string `json:"method"`
+
+	// Path the auth method is mounted at, defaults to the method name
+	// +kubebuilder:validation:Optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// Secret key holding a Vault token, for token auth
+	// +kubebuilder:validation:Optional
+	TokenSecretRef *SecretKeyRef `json:"tokenSecretRef,omitempty"`
+
+	// Kubernetes auth parameters; the operator's service account token is presented.
+	// Only a ClusterVaultConnection can use kubernetes auth.
+	// +kubebuilder:validation:Optional
+	Kubernetes *VaultKubernetesAuth `json:"kubernetes,omitempty"`
+
+	// AppRole auth parameters
+	// +kubebuilder:validation:Optional
+	AppRole *VaultAppRoleAuth `json:"appRole,omitempty"`
+}
+
+type VaultKubernetesAuth struct {
+	// Vault role bound to the operator's service account
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+}
+
+type VaultAppRoleAuth struct {
+	// AppRole role ID
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	RoleID string `json:"roleId"`
+
+	// Secret key holding the AppRole secret ID
+	// +kubebuilder:validation:Required
+	SecretIDRef SecretKeyRef `json:"secretIdRef"`
+}
+
+type SecretKeyRef struct {
+	// Name of the Secret
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Secret. Only used by ClusterVaultConnection;
+	// a VaultConnection always reads Secrets from its own namespace.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key within the Secret
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.spec.address`
+// +kubebuilder:printcolumn:name="Auth",type=string,JSONPath=`.spec.auth.method`
+
+// VaultConnection is the Schema for the vaultconnections API.
+type VaultConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec of the connection. It cannot use kubernetes auth, which would present
+	// the operator's service account token to an address chosen in the namespace.
+	// +kubebuilder:validation:XValidation:rule="self.auth.method != 'kubernetes'",message="kubernetes auth is only allowed on a ClusterVaultConnection"
+	Spec VaultConnectionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VaultConnectionList contains a list of VaultConnection.
+type VaultConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VaultConnection `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.spec.address`
+// +kubebuilder:printcolumn:name="Auth",type=string,JSONPath=`.spec.auth.method`
+
+// ClusterVaultConnection is the Schema for the clustervaultconnections API.
+// It can be referenced by requests in any namespace.
+type ClusterVaultConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VaultConnectionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterVaultConnectionList contains a list of ClusterVaultConnection.
+type ClusterVaultConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterVaultConnection `json:"items"`
+}
@@ -21,16 +21,21 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -42,6 +47,7 @@ import (
 
 	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
 	"github.com/floryn08/homelab-alm/internal/controller"
+	"github.com/floryn08/homelab-alm/internal/utils"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -71,6 +77,11 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var domainSource, domainSourcePath string
+	var clusterResourceNamespace string
+	var certificateBackend, internalCASecret, internalCAVaultPath string
+	var vaultPKIMount, vaultPKIRole string
+	var vaultWatchInterval, vaultCacheTTL, reconcileTimeout time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -89,6 +100,29 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&domainSource, "domain-source", utils.DomainSourceVault,
+		"The default source for domain lookups: Vault, ConfigMap, Secret or File.")
+	flag.StringVar(&domainSourcePath, "domain-source-path", "",
+		"The location of the domains for non-Vault sources: namespace/name for ConfigMap and Secret, "+
+			"or a file or directory path for File.")
+	flag.StringVar(&clusterResourceNamespace, "cluster-resource-namespace", controller.DefaultClusterResourceNamespace,
+		"cert-manager's cluster resource namespace, where IssuerProfile solver credentials are written.")
+	flag.StringVar(&certificateBackend, "certificate-backend", string(networkingv1.CertificateBackendCertManager),
+		"The backend for CertificateRequests that do not set spec.backend: CertManager, InternalCA or VaultPKI.")
+	flag.StringVar(&internalCASecret, "internal-ca-secret", "",
+		"The Secret (namespace/name) holding the InternalCA key pair; a self-signed CA is generated into it when missing.")
+	flag.StringVar(&internalCAVaultPath, "internal-ca-vault-path", "",
+		"The Vault KV path holding the InternalCA key pair (tls.crt, tls.key). Takes precedence over --internal-ca-secret.")
+	flag.StringVar(&vaultPKIMount, "vault-pki-mount", controller.DefaultVaultPKIMount,
+		"The Vault PKI mount used by the VaultPKI backend when a request does not set spec.vaultPKI.mount.")
+	flag.StringVar(&vaultPKIRole, "vault-pki-role", "",
+		"The Vault PKI role used by the VaultPKI backend when a request does not set spec.vaultPKI.role.")
+	flag.DurationVar(&vaultWatchInterval, "vault-watch-interval", time.Minute,
+		"How often referenced Vault paths are polled for domain changes. Set to 0 to disable.")
+	flag.DurationVar(&vaultCacheTTL, "vault-cache-ttl", utils.DefaultVaultCacheTTL,
+		"How long Vault reads are cached and shared between reconciles. Set to 0 to disable caching.")
+	flag.DurationVar(&reconcileTimeout, "reconcile-timeout", time.Minute,
+		"The deadline of each reconcile, including the Vault calls it makes.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -97,6 +131,8 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	utils.SetVaultCacheTTL(vaultCacheTTL)
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -192,6 +228,18 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "cde085fa.alm.homelab",
+		Controller: config.Controller{
+			ReconciliationTimeout: reconcileTimeout,
+		},
+		// Secrets are read uncached; only the copies made for replicateTo are watched
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+		},
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Secret{}: {Label: controller.SecretReplicaSelector()},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -209,20 +257,67 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Domain ConfigMaps and Secrets are read uncached so the manager does not watch every Secret in the cluster
+	vaultConnections := controller.NewVaultConnections(mgr.GetAPIReader())
+	domainSources := utils.NewDomainSources(mgr.GetAPIReader(), vaultConnections, domainSource, domainSourcePath)
+	if _, err := domainSources.Get(domainSource); err != nil {
+		setupLog.Error(err, "invalid domain source", "domain-source", domainSource)
+		os.Exit(1)
+	}
+
+	var vaultWatcher *controller.VaultWatcher
+	if vaultWatchInterval > 0 {
+		vaultWatcher = controller.NewVaultWatcher(mgr.GetClient(), domainSources, vaultConnections, vaultWatchInterval)
+		if err := mgr.Add(vaultWatcher); err != nil {
+			setupLog.Error(err, "unable to add Vault watcher to manager")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.IngressRequestReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Domains:      domainSources,
+		VaultWatcher: vaultWatcher,
+		Recorder:     mgr.GetEventRecorder("ingressrequest-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "IngressRequest")
 		os.Exit(1)
 	}
+	switch networkingv1.CertificateBackend(certificateBackend) {
+	case networkingv1.CertificateBackendCertManager, networkingv1.CertificateBackendInternalCA,
+		networkingv1.CertificateBackendVaultPKI:
+	default:
+		setupLog.Error(nil, "invalid certificate backend", "certificate-backend", certificateBackend)
+		os.Exit(1)
+	}
+	internalCA, err := controller.NewInternalCA(mgr.GetClient(), internalCASecret, internalCAVaultPath, vaultConnections)
+	if err != nil {
+		setupLog.Error(err, "invalid internal CA", "internal-ca-secret", internalCASecret)
+		os.Exit(1)
+	}
 	if err = (&controller.CertificateRequestReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Domains:        domainSources,
+		VaultWatcher:   vaultWatcher,
+		Recorder:       mgr.GetEventRecorder("certificaterequest-controller"),
+		DefaultBackend: networkingv1.CertificateBackend(certificateBackend),
+		InternalCA:     internalCA,
+		VaultPKI:       &controller.VaultPKI{Clients: vaultConnections, Mount: vaultPKIMount, Role: vaultPKIRole},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CertificateRequest")
 		os.Exit(1)
 	}
+	if err = (&controller.IssuerProfileReconciler{
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		ClusterResourceNamespace: clusterResourceNamespace,
+		ReadVault:                controller.VaultReader(vaultConnections),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "IssuerProfile")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
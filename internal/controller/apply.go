@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// fieldOwner is the field manager used for server-side apply of generated objects
+	fieldOwner = "homelab-alm"
+	// specHashAnnotation records the hash of the spec last applied to a generated object
+	specHashAnnotation = "networking.alm.homelab/spec-hash"
+	// eventReasonDriftCorrected is the event reason emitted when a generated object is reverted
+	eventReasonDriftCorrected = "DriftCorrected"
+)
+
+// specHash returns a stable hash of a generated object's spec
+func specHash(spec interface{}) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode spec: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// setSpecHash records hash on obj so later reconciles can tell drift from spec changes
+func setSpecHash(obj client.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[specHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+}
+
+// specDrifted reports whether the live object was changed by someone else since it was applied:
+// its recorded hash still matches the desired spec, but the live spec no longer contains it
+func specDrifted(live client.Object, hash string, desiredSpec, liveSpec interface{}) bool {
+	if live.GetAnnotations()[specHashAnnotation] != hash {
+		return false
+	}
+	return !equality.Semantic.DeepDerivative(desiredSpec, liveSpec)
+}
+
+// recordDrift emits a warning event on owner for a generated object whose manual changes are reverted
+func recordDrift(recorder events.EventRecorder, owner runtime.Object, live client.Object, kind string) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(owner, live, corev1.EventTypeWarning, eventReasonDriftCorrected, "Apply",
+		"%s %s was modified outside the operator; reverting to the desired spec", kind, live.GetName())
+}
+
+// applyObject server-side applies obj as fieldOwner, taking ownership of conflicting fields.
+// Only fields set on obj are owned, so fields added by other controllers are kept.
+// On success obj holds the object returned by the API server.
+func applyObject(ctx context.Context, c client.Client, scheme *runtime.Scheme, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return fmt.Errorf("failed to get GroupVersionKind: %w", err)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return fmt.Errorf("failed to convert object: %w", err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	unstructured.RemoveNestedField(u.Object, "status")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+
+	if err := c.Apply(ctx, client.ApplyConfigurationFromUnstructured(u), client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return fmt.Errorf("failed to convert applied object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+// autoCertificateSuffix names the Certificate of tls.autoCertificate, and its Secret unless tls.secretName is set
+const autoCertificateSuffix = "-tls"
+
+// usesAutoCertificate reports whether the operator provisions the certificate of ir
+func usesAutoCertificate(ir *networkingv1.IngressRequest) bool {
+	return ir.Spec.TLS != nil && ir.Spec.TLS.AutoCertificate && ingressProtocol(ir) != networkingv1.IngressProtocolUDP
+}
+
+// autoCertificateName returns the name of the Certificate generated for ir
+func autoCertificateName(ir *networkingv1.IngressRequest) string {
+	return ir.Name + autoCertificateSuffix
+}
+
+// autoCertificateSecretName returns the name of the Secret the Certificate of ir writes: tls.secretName,
+// or the name of the Certificate
+func autoCertificateSecretName(ir *networkingv1.IngressRequest) string {
+	return firstNonEmpty(ir.Spec.TLS.SecretName, autoCertificateName(ir))
+}
+
+// buildAutoCertificate constructs the Certificate for the FQDN of ir.
+// Issuer fields left empty on the request fall back to the domain entry, then to the operator defaults.
+func buildAutoCertificate(ir *networkingv1.IngressRequest, fqdn string, entry *utils.DomainEntry) *certmanagerv1.Certificate {
+	if entry == nil {
+		entry = &utils.DomainEntry{}
+	}
+	tlsSpec := ir.Spec.TLS
+
+	return &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      autoCertificateName(ir),
+			Namespace: ir.Namespace,
+		},
+		Spec: certmanagerv1.CertificateSpec{
+			SecretName:           autoCertificateSecretName(ir),
+			RevisionHistoryLimit: int32Ptr(1),
+			IssuerRef:            certificateIssuerRef(tlsSpec.IssuerName, tlsSpec.IssuerKind, tlsSpec.IssuerProfile, entry),
+			CommonName:           fqdn,
+			DNSNames:             []string{fqdn},
+		},
+	}
+}
+
+// withAutoCertificate returns the request to build the route of ir from: with tls.autoCertificate,
+// a copy whose tls.secretName is tlsSecret, the issued Secret, or empty while it is pending.
+// The secretName set on the request only names the Secret and is not used before it is issued.
+func withAutoCertificate(ir *networkingv1.IngressRequest, tlsSecret string) *networkingv1.IngressRequest {
+	if !usesAutoCertificate(ir) {
+		return ir
+	}
+	routed := ir.DeepCopy()
+	routed.Spec.TLS = &networkingv1.IngressTLSConfig{AutoCertificate: true, SecretName: tlsSecret}
+	return routed
+}
+
+// autoCertificateConflict returns a message naming the Certificate of tls.autoCertificate when it
+// exists without being controlled by ir, or its Secret when it exists without being issued for that
+// Certificate. It returns an empty string when there is no conflict.
+func (r *IngressRequestReconciler) autoCertificateConflict(ctx context.Context, ir *networkingv1.IngressRequest) (string, error) {
+	if !usesAutoCertificate(ir) {
+		return "", nil
+	}
+
+	var existing certmanagerv1.Certificate
+	key := client.ObjectKey{Namespace: ir.Namespace, Name: autoCertificateName(ir)}
+	if err := r.Get(ctx, key, &existing); err == nil {
+		if !metav1.IsControlledBy(&existing, ir) {
+			return fmt.Sprintf("Certificate %s exists and is not managed by this IngressRequest", key.Name), nil
+		}
+	} else if !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get Certificate %s: %w", key.Name, err)
+	}
+
+	// cert-manager would overwrite a Secret it did not issue for this Certificate
+	var secret corev1.Secret
+	secretKey := client.ObjectKey{Namespace: ir.Namespace, Name: autoCertificateSecretName(ir)}
+	if err := r.Get(ctx, secretKey, &secret); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get Secret %s: %w", secretKey.Name, err)
+	}
+	if secret.Annotations[certmanagerv1.CertificateNameKey] != key.Name {
+		return fmt.Sprintf("Secret %s exists and was not issued for Certificate %s", secretKey.Name, key.Name), nil
+	}
+	return "", nil
+}
+
+// syncAutoCertificate applies the Certificate of tls.autoCertificate and sets the CertificateIssued condition.
+// It returns the Secret to terminate TLS with once cert-manager issued it, and an empty string before.
+// Without tls.autoCertificate, a Certificate generated earlier is deleted; its Secret is kept.
+func (r *IngressRequestReconciler) syncAutoCertificate(ctx context.Context, ir *networkingv1.IngressRequest,
+	fqdn string, entry *utils.DomainEntry) (string, error) {
+	if !usesAutoCertificate(ir) {
+		if meta.FindStatusCondition(ir.Status.Conditions, networkingv1.ConditionCertificateIssued) == nil {
+			return "", nil
+		}
+		if err := r.deleteControlled(ctx, ir, &certmanagerv1.Certificate{}, autoCertificateName(ir)); err != nil {
+			return "", err
+		}
+		meta.RemoveStatusCondition(&ir.Status.Conditions, networkingv1.ConditionCertificateIssued)
+		return "", nil
+	}
+
+	cert := buildAutoCertificate(ir, fqdn, entry)
+	if err := ctrl.SetControllerReference(ir, cert, r.Scheme); err != nil {
+		return "", fmt.Errorf("failed to set controller reference: %w", err)
+	}
+	if err := r.applyGenerated(ctx, ir, cert, &certmanagerv1.Certificate{}, "Certificate",
+		func(obj client.Object) interface{} { return obj.(*certmanagerv1.Certificate).Spec }); err != nil {
+		return "", err
+	}
+
+	var secret corev1.Secret
+	key := client.ObjectKey{Namespace: ir.Namespace, Name: cert.Spec.SecretName}
+	if err := r.Get(ctx, key, &secret); err != nil && !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+	if len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0 {
+		setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionCertificateIssued, metav1.ConditionTrue,
+			networkingv1.ReasonIssued, fmt.Sprintf("Certificate %s issued to Secret %s", cert.Name, key.Name))
+		return key.Name, nil
+	}
+
+	reason := networkingv1.ReasonIssuing
+	message := fmt.Sprintf("Waiting for cert-manager to issue Secret %s; the route is served without TLS until then", key.Name)
+	for _, cond := range cert.Status.Conditions {
+		if cond.Type != certmanagerv1.CertificateConditionReady || cond.Status == cmmeta.ConditionTrue {
+			continue
+		}
+		if cert.Status.LastFailureTime != nil {
+			reason = networkingv1.ReasonIssuanceFailed
+		}
+		if cond.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, cond.Message)
+		}
+	}
+	setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionCertificateIssued, metav1.ConditionFalse,
+		reason, message)
+	return "", nil
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	traefikv1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+// TestBuildAutoCertificate validates the issuer of the generated Certificate
+func TestBuildAutoCertificate(t *testing.T) {
+	entry := &utils.DomainEntry{Domain: "example.com", IssuerName: "domain-issuer", IssuerKind: "Issuer"}
+
+	tests := []struct {
+		name     string
+		tlsSpec  networkingv1.IngressTLSConfig
+		entry    *utils.DomainEntry
+		wantName string
+		wantKind string
+	}{
+		{
+			name:     "operator defaults",
+			wantName: defaultIssuerName,
+			wantKind: defaultIssuerKind,
+		},
+		{
+			name:     "domain entry issuer",
+			entry:    entry,
+			wantName: "domain-issuer",
+			wantKind: "Issuer",
+		},
+		{
+			name:     "request issuer wins",
+			tlsSpec:  networkingv1.IngressTLSConfig{IssuerName: testIssuerName},
+			entry:    entry,
+			wantName: testIssuerName,
+			wantKind: "Issuer",
+		},
+		{
+			name:     "issuer profile",
+			tlsSpec:  networkingv1.IngressTLSConfig{IssuerProfile: "lab-acme"},
+			entry:    entry,
+			wantName: "lab-acme",
+			wantKind: certmanagerv1.ClusterIssuerKind,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.tlsSpec.AutoCertificate = true
+			ir := &networkingv1.IngressRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace},
+				Spec:       networkingv1.IngressRequestSpec{TLS: &tt.tlsSpec},
+			}
+
+			cert := buildAutoCertificate(ir, testFQDN, tt.entry)
+			if cert.Name != "test-ingress-tls" || cert.Spec.SecretName != "test-ingress-tls" {
+				t.Errorf("Certificate %s writes Secret %s, want test-ingress-tls for both", cert.Name, cert.Spec.SecretName)
+			}
+			if cert.Spec.CommonName != testFQDN || len(cert.Spec.DNSNames) != 1 || cert.Spec.DNSNames[0] != testFQDN {
+				t.Errorf("names = %s %v, want %s", cert.Spec.CommonName, cert.Spec.DNSNames, testFQDN)
+			}
+			if cert.Spec.IssuerRef.Name != tt.wantName || cert.Spec.IssuerRef.Kind != tt.wantKind {
+				t.Errorf("issuer = %s/%s, want %s/%s", cert.Spec.IssuerRef.Kind, cert.Spec.IssuerRef.Name, tt.wantKind, tt.wantName)
+			}
+		})
+	}
+}
+
+// TestIngressRequestAutoCertificate validates the route switches to TLS once the generated
+// Certificate's Secret is issued, and the Certificate is removed when no longer requested
+func TestIngressRequestAutoCertificate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = traefikv1alpha1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain:   testSubdomain,
+			DomainKey:   testDomainKey,
+			ServiceName: testServiceName,
+			Entrypoints: []string{"websecure"},
+			TLS:         &networkingv1.IngressTLSConfig{AutoCertificate: true},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: testServiceName, Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: testServicePort, Port: 80}}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(ir, svc).
+		WithStatusSubresource(&networkingv1.IngressRequest{}).
+		Build()
+	reconciler := &IngressRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(ir)
+	certKey := client.ObjectKey{Namespace: testNamespace, Name: "test-ingress-tls"}
+	reconcileAndGet := func() (*networkingv1.IngressRequest, *traefikv1alpha1.IngressRoute) {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var got networkingv1.IngressRequest
+		if err := fakeClient.Get(ctx, key, &got); err != nil {
+			t.Fatal(err)
+		}
+		var route traefikv1alpha1.IngressRoute
+		if err := fakeClient.Get(ctx, key, &route); err != nil {
+			t.Fatal(err)
+		}
+		return &got, &route
+	}
+	wantReady := func(got *networkingv1.IngressRequest, status metav1.ConditionStatus, reason string) {
+		t.Helper()
+		cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady)
+		if cond == nil || cond.Status != status || cond.Reason != reason {
+			t.Errorf("Ready condition = %+v, want %s/%s", cond, status, reason)
+		}
+	}
+
+	// The Certificate is created and the route waits for its Secret without TLS
+	got, route := reconcileAndGet()
+	var cert certmanagerv1.Certificate
+	if err := fakeClient.Get(ctx, certKey, &cert); err != nil {
+		t.Fatalf("expected Certificate: %v", err)
+	}
+	if owner := metav1.GetControllerOf(&cert); owner == nil || owner.Kind != "IngressRequest" || owner.Name != ir.Name {
+		t.Errorf("owner references = %v, want the IngressRequest", cert.OwnerReferences)
+	}
+	if cert.Spec.CommonName != testFQDN || cert.Spec.IssuerRef.Name != defaultIssuerName {
+		t.Errorf("Certificate = %s from %s, want %s from %s", cert.Spec.CommonName, cert.Spec.IssuerRef.Name, testFQDN, defaultIssuerName)
+	}
+	if route.Spec.TLS != nil {
+		t.Errorf("route TLS = %+v, want none before the Secret is issued", route.Spec.TLS)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionCertificateIssued); cond == nil ||
+		cond.Status != metav1.ConditionFalse {
+		t.Errorf("CertificateIssued condition = %+v, want False", cond)
+	}
+	wantReady(got, metav1.ConditionFalse, networkingv1.ReasonIssuing)
+
+	// Once cert-manager writes the Secret the route terminates TLS with it
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        certKey.Name,
+			Namespace:   testNamespace,
+			Annotations: map[string]string{certmanagerv1.CertificateNameKey: certKey.Name},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	if err := fakeClient.Create(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	got, route = reconcileAndGet()
+	if route.Spec.TLS == nil || route.Spec.TLS.SecretName != certKey.Name {
+		t.Errorf("route TLS = %+v, want Secret %s", route.Spec.TLS, certKey.Name)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, networkingv1.ConditionCertificateIssued) {
+		t.Error("CertificateIssued should be True")
+	}
+	wantReady(got, metav1.ConditionTrue, networkingv1.ReasonRouteReady)
+
+	// Turning it off deletes the Certificate and keeps the Secret
+	got.Spec.TLS = nil
+	if err := fakeClient.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	got, route = reconcileAndGet()
+	if err := fakeClient.Get(ctx, certKey, &certmanagerv1.Certificate{}); !errors.IsNotFound(err) {
+		t.Errorf("expected Certificate to be deleted, got %v", err)
+	}
+	if err := fakeClient.Get(ctx, certKey, &corev1.Secret{}); err != nil {
+		t.Errorf("expected Secret to be kept: %v", err)
+	}
+	if route.Spec.TLS != nil {
+		t.Errorf("route TLS = %+v, want none", route.Spec.TLS)
+	}
+	if meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionCertificateIssued) != nil {
+		t.Error("CertificateIssued should be removed")
+	}
+
+	// A Certificate created by someone else is not taken over
+	foreign := &certmanagerv1.Certificate{ObjectMeta: metav1.ObjectMeta{Name: certKey.Name, Namespace: testNamespace}}
+	if err := fakeClient.Create(ctx, foreign); err != nil {
+		t.Fatal(err)
+	}
+	got.Spec.TLS = &networkingv1.IngressTLSConfig{AutoCertificate: true}
+	if err := fakeClient.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = reconcileAndGet()
+	wantReady(got, metav1.ConditionFalse, networkingv1.ReasonCertificateConflict)
+	if err := fakeClient.Get(ctx, certKey, &cert); err != nil || cert.Spec.CommonName != "" {
+		t.Errorf("the foreign Certificate was overwritten: %+v, %v", cert.Spec, err)
+	}
+}
+
+// TestIngressRequestAutoCertificateSecret validates tls.secretName names the Secret of the generated
+// Certificate, and an existing Secret not issued for it is reported instead of overwritten
+func TestIngressRequestAutoCertificateSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = traefikv1alpha1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain:   testSubdomain,
+			DomainKey:   testDomainKey,
+			ServiceName: testServiceName,
+			Entrypoints: []string{"websecure"},
+			TLS:         &networkingv1.IngressTLSConfig{AutoCertificate: true, SecretName: testTLSSecretName},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: testServiceName, Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: testServicePort, Port: 80}}},
+	}
+	secretKey := client.ObjectKey{Namespace: testNamespace, Name: testTLSSecretName}
+	unrelated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: testNamespace},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(ir, svc, unrelated).
+		WithStatusSubresource(&networkingv1.IngressRequest{}).
+		Build()
+	reconciler := &IngressRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(ir)
+	certKey := client.ObjectKey{Namespace: testNamespace, Name: "test-ingress-tls"}
+	reconcileAndGet := func() (*networkingv1.IngressRequest, *traefikv1alpha1.IngressRoute) {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var got networkingv1.IngressRequest
+		if err := fakeClient.Get(ctx, key, &got); err != nil {
+			t.Fatal(err)
+		}
+		var route traefikv1alpha1.IngressRoute
+		if err := fakeClient.Get(ctx, key, &route); client.IgnoreNotFound(err) != nil {
+			t.Fatal(err)
+		}
+		return &got, &route
+	}
+	wantReady := func(got *networkingv1.IngressRequest, status metav1.ConditionStatus, reason string) {
+		t.Helper()
+		cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady)
+		if cond == nil || cond.Status != status || cond.Reason != reason {
+			t.Errorf("Ready condition = %+v, want %s/%s", cond, status, reason)
+		}
+	}
+
+	// A Secret that cert-manager did not issue for the Certificate is not taken over
+	got, _ := reconcileAndGet()
+	wantReady(got, metav1.ConditionFalse, networkingv1.ReasonCertificateConflict)
+	if err := fakeClient.Get(ctx, certKey, &certmanagerv1.Certificate{}); !errors.IsNotFound(err) {
+		t.Errorf("expected no Certificate while the Secret conflicts, got %v", err)
+	}
+
+	// Without the conflict the Certificate writes secretName, which the route waits for
+	if err := fakeClient.Delete(ctx, unrelated); err != nil {
+		t.Fatal(err)
+	}
+	got, route := reconcileAndGet()
+	var cert certmanagerv1.Certificate
+	if err := fakeClient.Get(ctx, certKey, &cert); err != nil {
+		t.Fatalf("expected Certificate: %v", err)
+	}
+	if cert.Spec.SecretName != secretKey.Name {
+		t.Errorf("Certificate writes Secret %s, want %s", cert.Spec.SecretName, secretKey.Name)
+	}
+	if route.Spec.TLS != nil {
+		t.Errorf("route TLS = %+v, want none before the Secret is issued", route.Spec.TLS)
+	}
+	wantReady(got, metav1.ConditionFalse, networkingv1.ReasonIssuing)
+
+	issued := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secretKey.Name,
+			Namespace:   testNamespace,
+			Annotations: map[string]string{certmanagerv1.CertificateNameKey: certKey.Name},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	if err := fakeClient.Create(ctx, issued); err != nil {
+		t.Fatal(err)
+	}
+	got, route = reconcileAndGet()
+	if route.Spec.TLS == nil || route.Spec.TLS.SecretName != secretKey.Name {
+		t.Errorf("route TLS = %+v, want Secret %s", route.Spec.TLS, secretKey.Name)
+	}
+	wantReady(got, metav1.ConditionTrue, networkingv1.ReasonRouteReady)
+}
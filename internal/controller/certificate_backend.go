@@ -0,0 +1,289 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+const (
+	// issuedForAnnotation marks TLS Secrets the operator issued itself with the name of their CertificateRequest
+	issuedForAnnotation = "networking.alm.homelab/certificate-request"
+
+	// minRenewalRequeue bounds how soon a request is requeued for renewal
+	minRenewalRequeue = time.Minute
+)
+
+// issuedCertificate is a certificate issued by a backend that writes the TLS Secret itself
+type issuedCertificate struct {
+	// ChainPEM holds the certificate followed by its intermediates
+	ChainPEM []byte
+	// PrivateKeyPEM is encoded as requested by spec.privateKey.encoding
+	PrivateKeyPEM []byte
+	// CAPEM is the root the chain leads to
+	CAPEM []byte
+}
+
+// directIssuer issues certificates for the backends that bypass cert-manager
+type directIssuer interface {
+	// reference identifies what issues the certificates, for status messages; a change reissues them
+	reference() string
+	// caPEM returns the CA certificate the backend currently issues from
+	caPEM(ctx context.Context) ([]byte, error)
+	// issue generates a key pair and signs a certificate for the spec of cert
+	issue(ctx context.Context, cert *certmanagerv1.Certificate) (*issuedCertificate, error)
+}
+
+// certificateBackend returns the backend serving cr: its own, then the operator default
+func (r *CertificateRequestReconciler) certificateBackend(cr *networkingv1.CertificateRequest) networkingv1.CertificateBackend {
+	if cr.Spec.Backend != "" {
+		return cr.Spec.Backend
+	}
+	if r.DefaultBackend != "" {
+		return r.DefaultBackend
+	}
+	return networkingv1.CertificateBackendCertManager
+}
+
+// directIssuer returns the issuer of cr for a backend other than CertManager
+func (r *CertificateRequestReconciler) directIssuer(ctx context.Context, cr *networkingv1.CertificateRequest,
+	backend networkingv1.CertificateBackend) (directIssuer, error) {
+	switch backend {
+	case networkingv1.CertificateBackendInternalCA:
+		if r.InternalCA == nil {
+			return nil, fmt.Errorf("the InternalCA backend is not configured on the operator")
+		}
+		return r.InternalCA, nil
+	case networkingv1.CertificateBackendVaultPKI:
+		vaultPKI := r.VaultPKI
+		if vaultPKI == nil {
+			vaultPKI = &VaultPKI{}
+		}
+		return vaultPKI.issuerFor(ctx, cr)
+	default:
+		return nil, fmt.Errorf("unknown certificate backend %q", backend)
+	}
+}
+
+// reconcileDirect issues the certificate of cr with a backend other than CertManager,
+// writing the TLS Secret itself and requeueing the request when it is due for renewal
+func (r *CertificateRequestReconciler) reconcileDirect(ctx context.Context, cr *networkingv1.CertificateRequest,
+	original *networkingv1.CertificateRequestStatus, cert *certmanagerv1.Certificate,
+	backend networkingv1.CertificateBackend) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	cr.Status.Issuer = ""
+	meta.RemoveStatusCondition(&cr.Status.Conditions, networkingv1.ConditionIssuerReady)
+
+	// A Certificate left from the CertManager backend would overwrite the Secret
+	if err := r.deleteCertificate(ctx, cr); err != nil {
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, cr, original, err)
+	}
+
+	issuer, err := r.directIssuer(ctx, cr, backend)
+	if err != nil {
+		logger.Error(err, "certificate backend unavailable", "backend", backend)
+		r.setIssuanceFailed(cr, err.Error())
+		return ctrl.Result{RequeueAfter: issuerRequeueInterval}, r.updateStatus(ctx, cr, original)
+	}
+
+	renewal, err := r.syncIssuedSecret(ctx, cr, cert, issuer)
+	if err != nil {
+		logger.Error(err, "failed to issue certificate", "backend", backend)
+		r.setIssuanceFailed(cr, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, cr, original, err)
+	}
+
+	if err := r.replicateSecret(ctx, cr); err != nil {
+		logger.Error(err, "failed to replicate Secret")
+		setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionSecretReplicated,
+			metav1.ConditionFalse, networkingv1.ReasonReplicationFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, cr, original, err)
+	}
+
+	return ctrl.Result{RequeueAfter: max(time.Until(renewal), minRenewalRequeue)}, r.updateStatus(ctx, cr, original)
+}
+
+// syncIssuedSecret writes the TLS Secret of cr, issuing a new certificate when there is none,
+// the spec or the CA changed, or renewal is due. It returns the renewal time.
+func (r *CertificateRequestReconciler) syncIssuedSecret(ctx context.Context, cr *networkingv1.CertificateRequest,
+	cert *certmanagerv1.Certificate, issuer directIssuer) (time.Time, error) {
+	key := client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.SecretName}
+	hash, err := issuanceHash(cert.Spec, issuer.reference())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var existing corev1.Secret
+	if err := r.Get(ctx, key, &existing); err == nil {
+		if !issuedForRequest(&existing, cr) {
+			return time.Time{}, fmt.Errorf("secret %s exists and was not issued for this request", key)
+		}
+	} else if !errors.IsNotFound(err) {
+		return time.Time{}, fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+
+	caPEM, err := issuer.caPEM(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	chainPEM, keyPEM := existing.Data[corev1.TLSCertKey], existing.Data[corev1.TLSPrivateKeyKey]
+	leaf, reason := currentCertificate(&existing, hash, caPEM)
+	if leaf == nil || !time.Now().Before(renewalTime(leaf, cert.Spec)) {
+		if leaf != nil {
+			reason = "renewal is due"
+		}
+		issued, err := issuer.issue(ctx, cert)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to issue certificate: %w", err)
+		}
+		if leaf, err = pki.DecodeX509CertificateBytes(issued.ChainPEM); err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse issued certificate: %w", err)
+		}
+		chainPEM, keyPEM, caPEM = issued.ChainPEM, issued.PrivateKeyPEM, issued.CAPEM
+		log.FromContext(ctx).Info("Issued certificate", "secret", key, "reason", reason, "notAfter", leaf.NotAfter)
+	}
+
+	secret, err := buildIssuedSecret(cr, cert, hash, chainPEM, keyPEM, caPEM)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := applyObject(ctx, r.Client, r.Scheme, secret); err != nil {
+		return time.Time{}, fmt.Errorf("failed to apply Secret %s: %w", key, err)
+	}
+
+	renewal := renewalTime(leaf, cert.Spec)
+	cr.Status.NotAfter = &metav1.Time{Time: leaf.NotAfter}
+	cr.Status.RenewalTime = &metav1.Time{Time: renewal}
+	message := fmt.Sprintf("Certificate issued by %s, valid until %s", issuer.reference(), leaf.NotAfter.UTC().Format(time.RFC3339))
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionCertificateIssued,
+		metav1.ConditionTrue, networkingv1.ReasonIssued, message)
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionReady,
+		metav1.ConditionTrue, networkingv1.ReasonIssued, message)
+	cr.Status.Ready = true
+
+	return renewal, nil
+}
+
+// setIssuanceFailed marks the certificate of cr as not issued
+func (r *CertificateRequestReconciler) setIssuanceFailed(cr *networkingv1.CertificateRequest, message string) {
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionCertificateIssued,
+		metav1.ConditionFalse, networkingv1.ReasonIssuanceFailed, message)
+	r.setNotReady(cr, networkingv1.ReasonIssuanceFailed, message)
+}
+
+// issuanceHash hashes the parts of a Certificate spec that end up in the certificate,
+// along with the issuer reference. The Secret template is applied without reissuing.
+func issuanceHash(spec certmanagerv1.CertificateSpec, reference string) (string, error) {
+	spec.IssuerRef = cmmeta.IssuerReference{Name: reference}
+	spec.SecretTemplate = nil
+	spec.RevisionHistoryLimit = nil
+	return specHash(spec)
+}
+
+// issuedForRequest reports whether secret was issued for cr, by this operator or by cert-manager
+func issuedForRequest(secret *corev1.Secret, cr *networkingv1.CertificateRequest) bool {
+	return secret.Annotations[issuedForAnnotation] == cr.Name ||
+		secret.Annotations[certmanagerv1.CertificateNameKey] == certificateName(cr)
+}
+
+// currentCertificate returns the certificate in secret when it was issued for hash by the CA in caPEM,
+// or nil and the reason it must be reissued
+func currentCertificate(secret *corev1.Secret, hash string, caPEM []byte) (*x509.Certificate, string) {
+	switch {
+	case len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0:
+		return nil, "no certificate issued"
+	case secret.Annotations[specHashAnnotation] != hash:
+		return nil, "spec changed"
+	case !bytes.Equal(secret.Data[cmmeta.TLSCAKey], caPEM):
+		return nil, "CA changed"
+	}
+
+	leaf, err := pki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, "invalid certificate"
+	}
+	return leaf, ""
+}
+
+// renewalTime returns when leaf is renewed: renewBefore ahead of expiry, or after two thirds of its lifetime
+func renewalTime(leaf *x509.Certificate, spec certmanagerv1.CertificateSpec) time.Time {
+	return pki.RenewalTime(leaf.NotBefore, leaf.NotAfter, spec.RenewBefore, nil).Time
+}
+
+// buildIssuedSecret constructs the TLS Secret for an issued certificate, including the
+// additional output formats and the labels and annotations of the Secret template
+func buildIssuedSecret(cr *networkingv1.CertificateRequest, cert *certmanagerv1.Certificate, hash string,
+	chainPEM, keyPEM, caPEM []byte) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        cr.Spec.SecretName,
+			Namespace:   cr.Namespace,
+			Annotations: map[string]string{},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       chainPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+			cmmeta.TLSCAKey:         caPEM,
+		},
+	}
+
+	if template := cert.Spec.SecretTemplate; template != nil {
+		secret.Labels = template.Labels
+		for k, v := range template.Annotations {
+			secret.Annotations[k] = v
+		}
+	}
+	secret.Annotations[issuedForAnnotation] = cr.Name
+	secret.Annotations[specHashAnnotation] = hash
+
+	for _, format := range cert.Spec.AdditionalOutputFormats {
+		switch format.Type {
+		case certmanagerv1.CertificateOutputFormatDER:
+			block, _ := pem.Decode(keyPEM)
+			if block == nil {
+				return nil, fmt.Errorf("failed to decode private key")
+			}
+			secret.Data[certmanagerv1.CertificateOutputFormatDERKey] = block.Bytes
+		case certmanagerv1.CertificateOutputFormatCombinedPEM:
+			combined := append(append(append([]byte{}, keyPEM...), '\n'), chainPEM...)
+			secret.Data[certmanagerv1.CertificateOutputFormatCombinedPEMKey] = combined
+		}
+	}
+
+	return secret, nil
+}
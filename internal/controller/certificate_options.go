@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+const (
+	// minDuration and minRenewBefore are the lowest values cert-manager accepts
+	minDuration    = time.Hour
+	minRenewBefore = 5 * time.Minute
+
+	minRSAKeySize = 2048
+	maxRSAKeySize = 8192
+
+	// certManagerAnnotationPrefix is reserved for annotations cert-manager sets on Secrets
+	certManagerAnnotationPrefix = "cert-manager.io/"
+)
+
+// validateCertificateOptions checks the key and lifetime options of a CertificateRequest
+// that the CRD schema cannot express
+func validateCertificateOptions(spec *networkingv1.CertificateRequestSpec) error {
+	if err := validatePrivateKey(spec.PrivateKey); err != nil {
+		return err
+	}
+
+	if spec.Duration != nil && spec.Duration.Duration < minDuration {
+		return fmt.Errorf("duration must be at least %s, got %s", minDuration, spec.Duration.Duration)
+	}
+	if spec.RenewBefore != nil {
+		if spec.RenewBefore.Duration < minRenewBefore {
+			return fmt.Errorf("renewBefore must be at least %s, got %s", minRenewBefore, spec.RenewBefore.Duration)
+		}
+		if spec.Duration != nil && spec.RenewBefore.Duration >= spec.Duration.Duration {
+			return fmt.Errorf("renewBefore (%s) must be less than duration (%s)", spec.RenewBefore.Duration, spec.Duration.Duration)
+		}
+	}
+
+	if err := validateSubject(spec.Subject); err != nil {
+		return err
+	}
+
+	return validateSecretTemplate(spec.SecretTemplate)
+}
+
+// validateSecretTemplate checks the Secret metadata is valid and leaves cert-manager's annotations alone
+func validateSecretTemplate(template *networkingv1.SecretTemplate) error {
+	if template == nil {
+		return nil
+	}
+
+	path := field.NewPath("spec", "secretTemplate")
+	errs := metav1validation.ValidateLabels(template.Labels, path.Child("labels"))
+	errs = append(errs, apivalidation.ValidateAnnotations(template.Annotations, path.Child("annotations"))...)
+	for key := range template.Annotations {
+		if strings.HasPrefix(key, certManagerAnnotationPrefix) {
+			errs = append(errs, field.Invalid(path.Child("annotations"), key, "cert-manager.io/ annotations are reserved"))
+		}
+	}
+	return errs.ToAggregate()
+}
+
+// buildSecretTemplate maps the request's Secret template onto cert-manager's
+func buildSecretTemplate(template *networkingv1.SecretTemplate) *certmanagerv1.CertificateSecretTemplate {
+	if template == nil || (len(template.Labels) == 0 && len(template.Annotations) == 0) {
+		return nil
+	}
+	return &certmanagerv1.CertificateSecretTemplate{
+		Labels:      template.Labels,
+		Annotations: template.Annotations,
+	}
+}
+
+// validatePrivateKey checks the key size fits the algorithm, which defaults to RSA
+func validatePrivateKey(key *networkingv1.CertificatePrivateKey) error {
+	if key == nil || key.Size == 0 {
+		return nil
+	}
+
+	switch key.Algorithm {
+	case "", string(certmanagerv1.RSAKeyAlgorithm):
+		if key.Size < minRSAKeySize || key.Size > maxRSAKeySize {
+			return fmt.Errorf("RSA key size must be between %d and %d, got %d", minRSAKeySize, maxRSAKeySize, key.Size)
+		}
+	case string(certmanagerv1.ECDSAKeyAlgorithm):
+		if key.Size != 256 && key.Size != 384 && key.Size != 521 {
+			return fmt.Errorf("ECDSA key size must be 256, 384 or 521, got %d", key.Size)
+		}
+	case string(certmanagerv1.Ed25519KeyAlgorithm):
+		return fmt.Errorf("size cannot be set for Ed25519 keys")
+	}
+
+	return nil
+}
+
+// buildPrivateKey maps the request's private key options onto cert-manager's
+func buildPrivateKey(key *networkingv1.CertificatePrivateKey) *certmanagerv1.CertificatePrivateKey {
+	if key == nil {
+		return nil
+	}
+	return &certmanagerv1.CertificatePrivateKey{
+		Algorithm:      certmanagerv1.PrivateKeyAlgorithm(key.Algorithm),
+		Size:           key.Size,
+		RotationPolicy: certmanagerv1.PrivateKeyRotationPolicy(key.RotationPolicy),
+		Encoding:       certmanagerv1.PrivateKeyEncoding(key.Encoding),
+	}
+}
+
+// buildKeyUsages maps the request's key usages onto cert-manager's
+func buildKeyUsages(usages []networkingv1.KeyUsage) []certmanagerv1.KeyUsage {
+	if len(usages) == 0 {
+		return nil
+	}
+	result := make([]certmanagerv1.KeyUsage, 0, len(usages))
+	for _, usage := range usages {
+		result = append(result, certmanagerv1.KeyUsage(usage))
+	}
+	return result
+}
+
+// buildOutputFormats maps the request's additional output formats onto cert-manager's
+func buildOutputFormats(formats []networkingv1.CertificateAdditionalOutputFormat) []certmanagerv1.CertificateAdditionalOutputFormat {
+	if len(formats) == 0 {
+		return nil
+	}
+	result := make([]certmanagerv1.CertificateAdditionalOutputFormat, 0, len(formats))
+	for _, format := range formats {
+		result = append(result, certmanagerv1.CertificateAdditionalOutputFormat{
+			Type: certmanagerv1.CertificateOutputFormatType(format.Type),
+		})
+	}
+	return result
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+// TestValidateCertificateOptions validates key sizes and lifetimes are checked
+func TestValidateCertificateOptions(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+
+	tests := []struct {
+		name      string
+		spec      networkingv1.CertificateRequestSpec
+		wantError bool
+	}{
+		{
+			name: "no options",
+		},
+		{
+			name: "ECDSA P-256 with short lifetime",
+			spec: networkingv1.CertificateRequestSpec{
+				PrivateKey:  &networkingv1.CertificatePrivateKey{Algorithm: "ECDSA", Size: 256},
+				Duration:    duration(24 * time.Hour),
+				RenewBefore: duration(8 * time.Hour),
+			},
+		},
+		{
+			name: "RSA size without algorithm",
+			spec: networkingv1.CertificateRequestSpec{
+				PrivateKey: &networkingv1.CertificatePrivateKey{Size: 4096},
+			},
+		},
+		{
+			name: "RSA key too small",
+			spec: networkingv1.CertificateRequestSpec{
+				PrivateKey: &networkingv1.CertificatePrivateKey{Algorithm: "RSA", Size: 1024},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid ECDSA size",
+			spec: networkingv1.CertificateRequestSpec{
+				PrivateKey: &networkingv1.CertificatePrivateKey{Algorithm: "ECDSA", Size: 2048},
+			},
+			wantError: true,
+		},
+		{
+			name: "Ed25519 with size",
+			spec: networkingv1.CertificateRequestSpec{
+				PrivateKey: &networkingv1.CertificatePrivateKey{Algorithm: "Ed25519", Size: 256},
+			},
+			wantError: true,
+		},
+		{
+			name: "secret template",
+			spec: networkingv1.CertificateRequestSpec{
+				SecretTemplate: &networkingv1.SecretTemplate{
+					Labels:      map[string]string{"velero.io/exclude-from-backup": "true"},
+					Annotations: map[string]string{"reflector.v1.k8s.emberstack.com/reflection-allowed": "true"},
+				},
+			},
+		},
+		{
+			name: "invalid secret template label",
+			spec: networkingv1.CertificateRequestSpec{
+				SecretTemplate: &networkingv1.SecretTemplate{Labels: map[string]string{"team": "not valid!"}},
+			},
+			wantError: true,
+		},
+		{
+			name: "reserved secret template annotation",
+			spec: networkingv1.CertificateRequestSpec{
+				SecretTemplate: &networkingv1.SecretTemplate{Annotations: map[string]string{"cert-manager.io/issuer-name": "x"}},
+			},
+			wantError: true,
+		},
+		{
+			name:      "duration too short",
+			spec:      networkingv1.CertificateRequestSpec{Duration: duration(30 * time.Minute)},
+			wantError: true,
+		},
+		{
+			name:      "renewBefore too short",
+			spec:      networkingv1.CertificateRequestSpec{RenewBefore: duration(time.Minute)},
+			wantError: true,
+		},
+		{
+			name: "renewBefore not less than duration",
+			spec: networkingv1.CertificateRequestSpec{
+				Duration:    duration(2 * time.Hour),
+				RenewBefore: duration(2 * time.Hour),
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCertificateOptions(&tt.spec)
+			if tt.wantError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestBuildCertificateOptions validates options are mapped onto the cert-manager Certificate
+func TestBuildCertificateOptions(t *testing.T) {
+	reconciler := &CertificateRequestReconciler{}
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName: testSecretName,
+			DomainKey:  testDomainKey,
+			PrivateKey: &networkingv1.CertificatePrivateKey{
+				Algorithm:      "ECDSA",
+				Size:           256,
+				RotationPolicy: "Always",
+				Encoding:       "PKCS8",
+			},
+			Duration:                &metav1.Duration{Duration: 24 * time.Hour},
+			RenewBefore:             &metav1.Duration{Duration: 8 * time.Hour},
+			Usages:                  []networkingv1.KeyUsage{"digital signature", "client auth"},
+			AdditionalOutputFormats: []networkingv1.CertificateAdditionalOutputFormat{{Type: "CombinedPEM"}},
+			SecretTemplate: &networkingv1.SecretTemplate{
+				Labels:      map[string]string{"velero.io/exclude-from-backup": "true"},
+				Annotations: map[string]string{"reflector.v1.k8s.emberstack.com/reflection-allowed": "true"},
+			},
+		},
+	}
+
+	cert, err := reconciler.buildCertificate(cr, "example.com", nil, nil)
+	if err != nil {
+		t.Fatalf("buildCertificate returned error: %v", err)
+	}
+	spec := cert.Spec
+
+	key := spec.PrivateKey
+	if key == nil || key.Algorithm != certmanagerv1.ECDSAKeyAlgorithm || key.Size != 256 ||
+		key.RotationPolicy != certmanagerv1.RotationPolicyAlways || key.Encoding != certmanagerv1.PKCS8 {
+		t.Errorf("PrivateKey = %+v", key)
+	}
+	if spec.Duration.Duration != 24*time.Hour || spec.RenewBefore.Duration != 8*time.Hour {
+		t.Errorf("Duration = %v, RenewBefore = %v", spec.Duration, spec.RenewBefore)
+	}
+	if len(spec.Usages) != 2 || spec.Usages[0] != certmanagerv1.UsageDigitalSignature || spec.Usages[1] != certmanagerv1.UsageClientAuth {
+		t.Errorf("Usages = %v", spec.Usages)
+	}
+	if len(spec.AdditionalOutputFormats) != 1 || spec.AdditionalOutputFormats[0].Type != certmanagerv1.CertificateOutputFormatCombinedPEM {
+		t.Errorf("AdditionalOutputFormats = %v", spec.AdditionalOutputFormats)
+	}
+
+	if spec.SecretTemplate == nil || spec.SecretTemplate.Labels["velero.io/exclude-from-backup"] != "true" ||
+		spec.SecretTemplate.Annotations["reflector.v1.k8s.emberstack.com/reflection-allowed"] != "true" {
+		t.Errorf("SecretTemplate = %+v", spec.SecretTemplate)
+	}
+
+	defaultCert, err := reconciler.buildCertificate(&networkingv1.CertificateRequest{}, "example.com", nil, nil)
+	if err != nil {
+		t.Fatalf("buildCertificate returned error: %v", err)
+	}
+	defaults := defaultCert.Spec
+	if defaults.PrivateKey != nil || defaults.Duration != nil || defaults.Usages != nil || defaults.AdditionalOutputFormats != nil ||
+		defaults.SecretTemplate != nil {
+		t.Errorf("expected cert-manager defaults when no options are set, got %+v", defaults)
+	}
+}
+
+// TestCertificateRequestSecretTemplateSync validates Secret template changes reach the Certificate
+func TestCertificateRequestSecretTemplateSync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName: testSecretName,
+			DomainKey:  testDomainKey,
+			SecretTemplate: &networkingv1.SecretTemplate{
+				Labels: map[string]string{"velero.io/exclude-from-backup": "true", "team": "platform"},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cr, testClusterIssuer()).
+		WithStatusSubresource(&networkingv1.CertificateRequest{}).
+		Build()
+	reconciler := &CertificateRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(cr)
+	reconcileAndGetLabels := func() map[string]string {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var cert certmanagerv1.Certificate
+		if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: certificateName(cr)}, &cert); err != nil {
+			t.Fatal(err)
+		}
+		if cert.Spec.SecretTemplate == nil {
+			return nil
+		}
+		return cert.Spec.SecretTemplate.Labels
+	}
+
+	if labels := reconcileAndGetLabels(); len(labels) != 2 {
+		t.Errorf("labels = %v, want both template labels", labels)
+	}
+
+	if err := fakeClient.Get(ctx, key, cr); err != nil {
+		t.Fatal(err)
+	}
+	cr.Spec.SecretTemplate.Labels = map[string]string{"team": "apps"}
+	if err := fakeClient.Update(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if labels := reconcileAndGetLabels(); len(labels) != 1 || labels["team"] != "apps" {
+		t.Errorf("labels = %v, want only team=apps", labels)
+	}
+
+	if err := fakeClient.Get(ctx, key, cr); err != nil {
+		t.Fatal(err)
+	}
+	cr.Spec.SecretTemplate = nil
+	if err := fakeClient.Update(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if labels := reconcileAndGetLabels(); len(labels) != 0 {
+		t.Errorf("labels = %v, want the template removed", labels)
+	}
+}
+
+// TestCertificateRequestReconcileInvalidOptions validates invalid options are reported without creating a Certificate
+func TestCertificateRequestReconcileInvalidOptions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName: testSecretName,
+			DomainKey:  testDomainKey,
+			Duration:   &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cr, testClusterIssuer()).
+		WithStatusSubresource(&networkingv1.CertificateRequest{}).
+		Build()
+	reconciler := &CertificateRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(cr)
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	var got networkingv1.CertificateRequest
+	if err := fakeClient.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != networkingv1.ReasonInvalidSpec {
+		t.Errorf("Ready condition = %+v, want False/%s", cond, networkingv1.ReasonInvalidSpec)
+	}
+
+	var certs certmanagerv1.CertificateList
+	if err := fakeClient.List(ctx, &certs); err != nil {
+		t.Fatal(err)
+	}
+	if len(certs.Items) != 0 {
+		t.Errorf("expected no Certificate for an invalid request, got %d", len(certs.Items))
+	}
+}
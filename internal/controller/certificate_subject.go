@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+// subjectTemplateData is what subject templates can reference
+type subjectTemplateData struct {
+	Name      string
+	Namespace string
+	FQDN      string
+	Domain    string
+	Subdomain string
+	Values    map[string]string
+}
+
+// validateSubject checks every subject template parses
+func validateSubject(subject *networkingv1.CertificateSubject) error {
+	if subject == nil {
+		return nil
+	}
+	for _, text := range subjectTemplates(subject) {
+		if _, err := parseSubjectTemplate(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSubject renders the request's subject, or returns the subject derived from the
+// domain of entry when it sets none
+func buildSubject(cr *networkingv1.CertificateRequest, fqdn string,
+	entry *utils.DomainEntry) (*certmanagerv1.X509Subject, error) {
+	subject := cr.Spec.Subject
+	if subject == nil {
+		return &certmanagerv1.X509Subject{
+			Organizations:       []string{entry.Domain},
+			OrganizationalUnits: []string{cr.Namespace},
+		}, nil
+	}
+
+	data := subjectTemplateData{
+		Name:      cr.Name,
+		Namespace: cr.Namespace,
+		FQDN:      fqdn,
+		Domain:    entry.Domain,
+		Subdomain: cr.Spec.Subdomain,
+		Values:    entry.Values,
+	}
+	if data.Values == nil {
+		data.Values = map[string]string{}
+	}
+
+	var err error
+	result := &certmanagerv1.X509Subject{}
+	if result.Organizations, err = renderSubjectValues(subject.Organizations, data); err != nil {
+		return nil, err
+	}
+	if result.OrganizationalUnits, err = renderSubjectValues(subject.OrganizationalUnits, data); err != nil {
+		return nil, err
+	}
+	if result.Countries, err = renderSubjectValues(subject.Countries, data); err != nil {
+		return nil, err
+	}
+	if result.Provinces, err = renderSubjectValues(subject.Provinces, data); err != nil {
+		return nil, err
+	}
+	if result.Localities, err = renderSubjectValues(subject.Localities, data); err != nil {
+		return nil, err
+	}
+	if result.SerialNumber, err = renderSubjectValue(subject.SerialNumber, data); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// subjectTemplates returns every template of subject
+func subjectTemplates(subject *networkingv1.CertificateSubject) []string {
+	var templates []string
+	templates = append(templates, subject.Organizations...)
+	templates = append(templates, subject.OrganizationalUnits...)
+	templates = append(templates, subject.Countries...)
+	templates = append(templates, subject.Provinces...)
+	templates = append(templates, subject.Localities...)
+	return append(templates, subject.SerialNumber)
+}
+
+// renderSubjectValues renders each template, dropping values that render empty
+func renderSubjectValues(templates []string, data subjectTemplateData) ([]string, error) {
+	var values []string
+	for _, text := range templates {
+		value, err := renderSubjectValue(text, data)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// renderSubjectValue renders a single template; referencing a missing value is an error
+func renderSubjectValue(text string, data subjectTemplateData) (string, error) {
+	tmpl, err := parseSubjectTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render subject value %q: %w", text, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// parseSubjectTemplate parses a subject template
+func parseSubjectTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("subject").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template %q: %w", text, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+// TestBuildSubject validates subject templates are rendered from the request and domain entry
+func TestBuildSubject(t *testing.T) {
+	entry := &utils.DomainEntry{
+		Domain: "example.com",
+		Values: map[string]string{"organization": "Homelab", "country": "RO"},
+	}
+
+	tests := []struct {
+		name      string
+		subject   *networkingv1.CertificateSubject
+		want      *certmanagerv1.X509Subject
+		wantError bool
+	}{
+		{
+			name: "domain-derived default",
+			want: &certmanagerv1.X509Subject{
+				Organizations:       []string{"example.com"},
+				OrganizationalUnits: []string{testNamespace},
+			},
+		},
+		{
+			name: "templated fields",
+			subject: &networkingv1.CertificateSubject{
+				Organizations:       []string{"{{ .Values.organization }}"},
+				OrganizationalUnits: []string{"{{ .Namespace }}", "apps"},
+				Countries:           []string{"{{ .Values.country }}"},
+				Localities:          []string{"Bucharest"},
+				SerialNumber:        "{{ .Namespace }}-{{ .Name }}",
+			},
+			want: &certmanagerv1.X509Subject{
+				Organizations:       []string{"Homelab"},
+				OrganizationalUnits: []string{testNamespace, "apps"},
+				Countries:           []string{"RO"},
+				Localities:          []string{"Bucharest"},
+				SerialNumber:        testNamespace + "-test-cert",
+			},
+		},
+		{
+			name: "empty values are dropped",
+			subject: &networkingv1.CertificateSubject{
+				Organizations: []string{"{{ .FQDN }}", `{{ if eq .Subdomain "" }}apex{{ end }}`},
+			},
+			want: &certmanagerv1.X509Subject{
+				Organizations: []string{testFQDN},
+			},
+		},
+		{
+			name: "missing value",
+			subject: &networkingv1.CertificateSubject{
+				Organizations: []string{"{{ .Values.unit }}"},
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+				Spec:       networkingv1.CertificateRequestSpec{Subdomain: testSubdomain, Subject: tt.subject},
+			}
+
+			got, err := buildSubject(cr, testFQDN, entry)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error, got subject %+v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subject = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildCertificateSubjectDomain validates the subject uses the resolved domain of the entry
+func TestBuildCertificateSubjectDomain(t *testing.T) {
+	reconciler := &CertificateRequestReconciler{}
+	entry := &utils.DomainEntry{Domain: "example.com"}
+
+	for _, subject := range []*networkingv1.CertificateSubject{nil, {Organizations: []string{"{{ .Domain }}"}}} {
+		cr := &networkingv1.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+			Spec:       networkingv1.CertificateRequestSpec{Subdomain: "www.app", Subject: subject},
+		}
+
+		cert, err := reconciler.buildCertificate(cr, "www.app.example.com", nil, entry)
+		if err != nil {
+			t.Fatalf("buildCertificate returned error: %v", err)
+		}
+		if got := cert.Spec.Subject.Organizations; !reflect.DeepEqual(got, []string{"example.com"}) {
+			t.Errorf("Organizations = %v, want [example.com]", got)
+		}
+	}
+}
+
+// TestValidateSubject validates subject templates are parsed before they are used
+func TestValidateSubject(t *testing.T) {
+	valid := &networkingv1.CertificateSubject{Organizations: []string{"{{ .Values.organization }}"}}
+	if err := validateSubject(valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	invalid := &networkingv1.CertificateSubject{SerialNumber: "{{ .Name "}
+	if err := validateSubject(invalid); err == nil {
+		t.Error("Expected error for an unterminated template")
+	}
+}
@@ -24,26 +24,55 @@ import (
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
 	"github.com/floryn08/homelab-alm/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-const defaultIssuerKind = "ClusterIssuer"
+const (
+	defaultIssuerName = "ca-issuer"
+	defaultIssuerKind = "ClusterIssuer"
+
+	// certificateRequestFinalizer lets the operator clean up after a CertificateRequest is deleted
+	certificateRequestFinalizer = "networking.alm.homelab/cleanup"
+)
 
 // CertificateRequestReconciler reconciles a CertificateRequest object
 type CertificateRequestReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Domains resolves domain keys; Vault is used when nil
+	Domains *utils.DomainSources
+	// VaultWatcher enqueues requests whose Vault domain changed (optional)
+	VaultWatcher *VaultWatcher
+	// Recorder emits events when a generated Certificate drifted (optional)
+	Recorder events.EventRecorder
+	// DefaultBackend serves requests that do not set spec.backend; CertManager when empty
+	DefaultBackend networkingv1.CertificateBackend
+	// InternalCA signs certificates for the InternalCA backend (optional)
+	InternalCA *InternalCA
+	// VaultPKI configures the VaultPKI backend; the environment's Vault and the request's role are used when nil
+	VaultPKI *VaultPKI
 }
 
 // +kubebuilder:rbac:groups=networking.alm.homelab,resources=certificaterequests,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.alm.homelab,resources=certificaterequests/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=networking.alm.homelab,resources=certificaterequests/finalizers,verbs=update
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=issuers;clusterissuers,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.alm.homelab,resources=vaultconnections;clustervaultconnections,verbs=get
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -58,138 +87,398 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// Fetch domain from Vault
-	fqdn, err := r.getFQDN(&cr)
+	if !cr.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, &cr)
+	}
+
+	if controllerutil.AddFinalizer(&cr, certificateRequestFinalizer) {
+		if err := r.Update(ctx, &cr); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+
+	original := cr.Status.DeepCopy()
+
+	// Reject options cert-manager would refuse; a spec change triggers the next reconcile
+	if err := validateCertificateOptions(&cr.Spec); err != nil {
+		logger.Error(err, "invalid CertificateRequest")
+		r.setNotReady(&cr, networkingv1.ReasonInvalidSpec, err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &cr, original)
+	}
+
+	// Fetch domain from the domain source
+	fqdn, entry, err := r.getFQDN(ctx, &cr)
+	var dnsNames []string
+	if err == nil {
+		dnsNames, err = r.resolveDNSNames(ctx, &cr, fqdn, entry)
+	}
 	if err != nil {
 		logger.Error(err, "failed to construct FQDN")
-		return ctrl.Result{}, err
+		setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionVaultResolved,
+			metav1.ConditionFalse, networkingv1.ReasonDomainLookupFailed, err.Error())
+		r.setNotReady(&cr, networkingv1.ReasonDomainLookupFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &cr, original, err)
 	}
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionVaultResolved,
+		metav1.ConditionTrue, networkingv1.ReasonDomainResolved, fmt.Sprintf("Domain key %s resolved to %s", cr.Spec.DomainKey, entry.Domain))
+	cr.Status.FQDN = fqdn
+	cr.Status.DNSNames = dnsNames
 
 	// Create or update the Certificate
-	cert := r.buildCertificate(&cr, fqdn)
+	cert, err := r.buildCertificate(&cr, fqdn, dnsNames, entry)
+	if err != nil {
+		logger.Error(err, "failed to build Certificate")
+		r.setNotReady(&cr, networkingv1.ReasonInvalidSpec, err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &cr, original)
+	}
 	if err := ctrl.SetControllerReference(&cr, cert, r.Scheme); err != nil {
 		logger.Error(err, "failed to set controller reference")
 		return ctrl.Result{}, err
 	}
 
-	if err := r.createOrUpdateCertificate(ctx, cert); err != nil {
-		logger.Error(err, "failed to create or update Certificate")
-		return ctrl.Result{}, err
+	// Backends other than cert-manager issue the Secret themselves
+	backend := r.certificateBackend(&cr)
+	cr.Status.Backend = backend
+	if backend != networkingv1.CertificateBackendCertManager {
+		return r.reconcileDirect(ctx, &cr, original, cert, backend)
+	}
+
+	// Wait for the issuer; the issuer watches and a periodic requeue pick the request up again
+	cr.Status.Issuer = issuerStatus(cert.Spec.IssuerRef)
+	issuerReason, issuerMessage, err := r.checkIssuer(ctx, cr.Namespace, cert.Spec.IssuerRef)
+	if err != nil {
+		logger.Error(err, "failed to check issuer")
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &cr, original, err)
+	}
+	issuerReady := issuerReason == networkingv1.ReasonIssuerReady
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionIssuerReady,
+		conditionStatus(issuerReady), issuerReason, issuerMessage)
+	if !issuerReady {
+		logger.Info("Waiting for issuer", "issuer", cr.Status.Issuer, "reason", issuerMessage)
+		r.setNotReady(&cr, issuerReason, issuerMessage)
+		return ctrl.Result{RequeueAfter: issuerRequeueInterval}, r.updateStatus(ctx, &cr, original)
+	}
+
+	if err := r.applyCertificate(ctx, &cr, cert); err != nil {
+		logger.Error(err, "failed to apply Certificate")
+		r.setNotReady(&cr, networkingv1.ReasonCertificateSyncFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &cr, original, err)
 	}
 
 	logger.Info("Successfully reconciled Certificate", "fqdn", fqdn)
 
-	// Update status
-	return r.updateStatus(ctx, &cr, fqdn)
-}
+	// Update status from the Certificate cert-manager reports on
+	mirrorCertificateStatus(&cr, cert)
 
-// getFQDN constructs the FQDN by fetching the domain from Vault
-func (r *CertificateRequestReconciler) getFQDN(cr *networkingv1.CertificateRequest) (string, error) {
-	vaultPath := cr.Spec.VaultPath
-	if vaultPath == "" {
-		vaultPath = "kv/data/domains"
+	// Copy the issued Secret; renewals update the Certificate status, which requeues the request
+	if err := r.replicateSecret(ctx, &cr); err != nil {
+		logger.Error(err, "failed to replicate Secret")
+		setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionSecretReplicated,
+			metav1.ConditionFalse, networkingv1.ReasonReplicationFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &cr, original, err)
 	}
 
-	domain, err := utils.GetDomainFromVault(vaultPath, cr.Spec.DomainKey)
+	return ctrl.Result{}, r.updateStatus(ctx, &cr, original)
+}
+
+// getFQDN constructs the FQDN by fetching the domain from the request's domain source.
+// It also returns the domain entry, whose defaults apply to fields the request leaves empty.
+func (r *CertificateRequestReconciler) getFQDN(ctx context.Context,
+	cr *networkingv1.CertificateRequest) (string, *utils.DomainEntry, error) {
+	entry, err := resolveDomain(ctx, r.Domains, certificateDomainRequest(cr))
 	if err != nil {
-		return "", fmt.Errorf("failed to get domain from Vault: %w", err)
+		return "", nil, err
 	}
 
-	if cr.Spec.Subdomain == "" {
-		return domain, nil
+	return joinDomain(cr.Spec.Subdomain, entry.Domain), entry, nil
+}
+
+// resolveDNSNames returns the certificate's DNS names: fqdn followed by the distinct names
+// built from spec.dnsNames. Each domain key is looked up once.
+func (r *CertificateRequestReconciler) resolveDNSNames(ctx context.Context, cr *networkingv1.CertificateRequest,
+	fqdn string, entry *utils.DomainEntry) ([]string, error) {
+	entries := map[string]*utils.DomainEntry{cr.Spec.DomainKey: entry}
+	names := []string{fqdn}
+	seen := map[string]bool{fqdn: true}
+
+	for _, dnsName := range cr.Spec.DNSNames {
+		key := firstNonEmpty(dnsName.DomainKey, cr.Spec.DomainKey)
+		keyEntry, ok := entries[key]
+		if !ok {
+			var err error
+			keyEntry, err = resolveDomain(ctx, r.Domains, certificateDomainRequest(cr).withKey(key))
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve domain key %s: %w", key, err)
+			}
+			entries[key] = keyEntry
+		}
+
+		name := joinDomain(dnsName.Subdomain, keyEntry.Domain)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
 	}
 
-	return fmt.Sprintf("%s.%s", cr.Spec.Subdomain, domain), nil
+	return names, nil
 }
 
-// buildCertificate constructs the desired Certificate resource
-func (r *CertificateRequestReconciler) buildCertificate(cr *networkingv1.CertificateRequest, fqdn string) *certmanagerv1.Certificate {
-	issuerName := cr.Spec.IssuerName
-	if issuerName == "" {
-		issuerName = "ca-issuer"
+// buildCertificate constructs the desired Certificate resource for fqdn and dnsNames (just fqdn when empty).
+// Issuer fields left empty on the request fall back to the domain entry, then to the operator defaults;
+// an issuerProfile selects the ClusterIssuer generated from it.
+// It fails when the subject templates cannot be rendered.
+func (r *CertificateRequestReconciler) buildCertificate(cr *networkingv1.CertificateRequest, fqdn string,
+	dnsNames []string, entry *utils.DomainEntry) (*certmanagerv1.Certificate, error) {
+	if entry == nil {
+		entry = &utils.DomainEntry{}
 	}
-
-	issuerKind := cr.Spec.IssuerKind
-	if issuerKind == "" {
-		issuerKind = defaultIssuerKind
+	if len(dnsNames) == 0 {
+		dnsNames = []string{fqdn}
 	}
 
-	// Extract base domain for organization
-	domain := fqdn
-	if cr.Spec.Subdomain != "" {
-		// Remove subdomain to get base domain
-		domain = fqdn[len(cr.Spec.Subdomain)+1:]
+	subject, err := buildSubject(cr, fqdn, entry)
+	if err != nil {
+		return nil, err
 	}
 
 	return &certmanagerv1.Certificate{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name + "-certificate",
+			Name:      certificateName(cr),
 			Namespace: cr.Namespace,
 		},
 		Spec: certmanagerv1.CertificateSpec{
-			SecretName:           cr.Spec.SecretName,
-			RevisionHistoryLimit: int32Ptr(1),
-			IssuerRef: cmmeta.IssuerReference{
-				Name: issuerName,
-				Kind: issuerKind,
-			},
-			CommonName: fqdn,
-			DNSNames:   []string{fqdn},
-			Subject: &certmanagerv1.X509Subject{
-				Organizations:       []string{domain},
-				OrganizationalUnits: []string{cr.Namespace},
-			},
+			SecretName:              cr.Spec.SecretName,
+			RevisionHistoryLimit:    int32Ptr(1),
+			IssuerRef:               certificateIssuerRef(cr.Spec.IssuerName, cr.Spec.IssuerKind, cr.Spec.IssuerProfile, entry),
+			CommonName:              fqdn,
+			DNSNames:                dnsNames,
+			Duration:                cr.Spec.Duration,
+			RenewBefore:             cr.Spec.RenewBefore,
+			PrivateKey:              buildPrivateKey(cr.Spec.PrivateKey),
+			Usages:                  buildKeyUsages(cr.Spec.Usages),
+			AdditionalOutputFormats: buildOutputFormats(cr.Spec.AdditionalOutputFormats),
+			SecretTemplate:          buildSecretTemplate(cr.Spec.SecretTemplate),
+			Subject:                 subject,
 		},
+	}, nil
+}
+
+// certificateIssuerRef returns the issuer of a Certificate. Name and kind left empty fall back to the
+// domain entry, then to the operator defaults; a profile selects the ClusterIssuer generated from it.
+func certificateIssuerRef(name, kind, profile string, entry *utils.DomainEntry) cmmeta.IssuerReference {
+	if profile != "" {
+		// Profiles generate a ClusterIssuer of the same name
+		return cmmeta.IssuerReference{Name: profile, Kind: certmanagerv1.ClusterIssuerKind}
+	}
+	return cmmeta.IssuerReference{
+		Name: firstNonEmpty(name, entry.IssuerName, defaultIssuerName),
+		Kind: firstNonEmpty(kind, entry.IssuerKind, defaultIssuerKind),
 	}
 }
 
-// createOrUpdateCertificate creates or updates the Certificate resource
-func (r *CertificateRequestReconciler) createOrUpdateCertificate(ctx context.Context, cert *certmanagerv1.Certificate) error {
+// applyCertificate server-side applies the Certificate, reporting manual changes to it as drift
+func (r *CertificateRequestReconciler) applyCertificate(ctx context.Context, cr *networkingv1.CertificateRequest,
+	cert *certmanagerv1.Certificate) error {
 	logger := log.FromContext(ctx)
 
+	hash, err := specHash(cert.Spec)
+	if err != nil {
+		return err
+	}
+	setSpecHash(cert, hash)
+
 	var existing certmanagerv1.Certificate
-	err := r.Get(ctx, client.ObjectKeyFromObject(cert), &existing)
+	if err := r.Get(ctx, client.ObjectKeyFromObject(cert), &existing); err == nil {
+		if specDrifted(&existing, hash, cert.Spec, existing.Spec) {
+			logger.Info("Reverting drifted Certificate", "name", cert.Name, "namespace", cert.Namespace)
+			recordDrift(r.Recorder, cr, &existing, "Certificate")
+		}
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get existing Certificate: %w", err)
+	}
+
+	if err := applyObject(ctx, r.Client, r.Scheme, cert); err != nil {
+		return fmt.Errorf("failed to apply Certificate: %w", err)
+	}
+
+	logger.Info("Applied Certificate", "name", cert.Name, "namespace", cert.Namespace)
+	return nil
+}
+
+// finalize removes the Secret copies, the Certificate and, with the Delete policy, the TLS Secret of cr,
+// then releases the finalizer
+func (r *CertificateRequestReconciler) finalize(ctx context.Context, cr *networkingv1.CertificateRequest) error {
+	if !controllerutil.ContainsFinalizer(cr, certificateRequestFinalizer) {
+		return nil
+	}
 
-	if errors.IsNotFound(err) {
-		if err := r.Create(ctx, cert); err != nil {
-			return fmt.Errorf("failed to create Certificate: %w", err)
+	if _, err := r.pruneReplicas(ctx, cr, nil); err != nil {
+		return err
+	}
+
+	// Delete the Certificate first so cert-manager does not recreate the Secret
+	if err := r.deleteCertificate(ctx, cr); err != nil {
+		return err
+	}
+
+	if cr.Spec.DeletionPolicy == networkingv1.DeletionPolicyDelete {
+		if err := r.deleteSecret(ctx, cr); err != nil {
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(cr, certificateRequestFinalizer)
+	if err := r.Update(ctx, cr); err != nil {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return nil
+}
+
+// deleteCertificate deletes the Certificate generated for cr, if any
+func (r *CertificateRequestReconciler) deleteCertificate(ctx context.Context, cr *networkingv1.CertificateRequest) error {
+	var cert certmanagerv1.Certificate
+	key := client.ObjectKey{Namespace: cr.Namespace, Name: certificateName(cr)}
+	if err := r.Get(ctx, key, &cert); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
 		}
-		logger.Info("Created Certificate", "name", cert.Name, "namespace", cert.Namespace)
+		return fmt.Errorf("failed to get Certificate: %w", err)
+	}
+
+	if !metav1.IsControlledBy(&cert, cr) {
 		return nil
 	}
+	if err := r.Delete(ctx, &cert); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete Certificate: %w", err)
+	}
+	log.FromContext(ctx).Info("Deleted Certificate", "name", cert.Name, "namespace", cert.Namespace)
+	return nil
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to get existing Certificate: %w", err)
+// deleteSecret deletes the TLS Secret of cr. Secrets that were not issued for the
+// request, by cert-manager or by the operator, are left alone.
+func (r *CertificateRequestReconciler) deleteSecret(ctx context.Context, cr *networkingv1.CertificateRequest) error {
+	var secret corev1.Secret
+	key := client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.SecretName}
+	if err := r.Get(ctx, key, &secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Secret %s: %w", key, err)
 	}
 
-	// Update existing certificate
-	cert.ResourceVersion = existing.ResourceVersion
-	if err := r.Update(ctx, cert); err != nil {
-		return fmt.Errorf("failed to update Certificate: %w", err)
+	if !issuedForRequest(&secret, cr) {
+		log.FromContext(ctx).Info("Keeping Secret not issued for this request", "secret", key)
+		return nil
 	}
 
-	logger.Info("Updated Certificate", "name", cert.Name, "namespace", cert.Namespace)
+	if err := r.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete Secret %s: %w", key, err)
+	}
+	log.FromContext(ctx).Info("Deleted Secret", "secret", key)
 	return nil
 }
 
-// updateStatus updates the CertificateRequest status
-func (r *CertificateRequestReconciler) updateStatus(ctx context.Context, cr *networkingv1.CertificateRequest, fqdn string) (ctrl.Result, error) {
-	cr.Status.FQDN = fqdn
-	cr.Status.Ready = true
+// certificateName returns the name of the Certificate generated for cr
+func certificateName(cr *networkingv1.CertificateRequest) string {
+	return cr.Name + "-certificate"
+}
+
+// mirrorCertificateStatus copies the issuance state of cert into the CertificateRequest status
+func mirrorCertificateStatus(cr *networkingv1.CertificateRequest, cert *certmanagerv1.Certificate) {
+	cr.Status.NotAfter = cert.Status.NotAfter
+	cr.Status.RenewalTime = cert.Status.RenewalTime
+
+	var ready *certmanagerv1.CertificateCondition
+	for i := range cert.Status.Conditions {
+		if cert.Status.Conditions[i].Type == certmanagerv1.CertificateConditionReady {
+			ready = &cert.Status.Conditions[i]
+		}
+	}
+
+	status := metav1.ConditionUnknown
+	reason := networkingv1.ReasonIssuing
+	message := "Waiting for cert-manager to issue the Certificate"
+	switch {
+	case ready == nil:
+	case ready.Status == cmmeta.ConditionTrue:
+		status, reason, message = metav1.ConditionTrue, networkingv1.ReasonIssued, ready.Message
+	case cert.Status.LastFailureTime != nil:
+		status, reason, message = metav1.ConditionFalse, networkingv1.ReasonIssuanceFailed, ready.Message
+	default:
+		status, message = metav1.ConditionFalse, ready.Message
+	}
+
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionCertificateIssued, status, reason, message)
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionReady, status, reason, message)
+	cr.Status.Ready = status == metav1.ConditionTrue
+}
+
+// setNotReady marks the CertificateRequest as not ready for reason
+func (r *CertificateRequestReconciler) setNotReady(cr *networkingv1.CertificateRequest, reason, message string) {
+	setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionReady, metav1.ConditionFalse, reason, message)
+	cr.Status.Ready = false
+}
+
+// updateStatus writes the CertificateRequest status when it differs from original
+func (r *CertificateRequestReconciler) updateStatus(ctx context.Context, cr *networkingv1.CertificateRequest,
+	original *networkingv1.CertificateRequestStatus) error {
+	cr.Status.ObservedGeneration = cr.Generation
+	if equality.Semantic.DeepEqual(*original, cr.Status) {
+		return nil
+	}
 
 	if err := r.Status().Update(ctx, cr); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+		return fmt.Errorf("failed to update status: %w", err)
 	}
 
-	return ctrl.Result{}, nil
+	return nil
+}
+
+// updateStatusAfterError records a failed reconcile in the status and returns the original error
+func (r *CertificateRequestReconciler) updateStatusAfterError(ctx context.Context, cr *networkingv1.CertificateRequest,
+	original *networkingv1.CertificateRequestStatus, err error) error {
+	if statusErr := r.updateStatus(ctx, cr, original); statusErr != nil {
+		log.FromContext(ctx).Error(statusErr, "failed to record error in status")
+	}
+	return err
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *CertificateRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &networkingv1.CertificateRequest{}, vaultDomainIndex,
+		func(obj client.Object) []string {
+			cr := obj.(*networkingv1.CertificateRequest)
+			return vaultDomainIndexValues(r.Domains, certificateDomainRequests(cr)...)
+		}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &networkingv1.CertificateRequest{}, issuerIndex,
+		func(obj client.Object) []string {
+			cr := obj.(*networkingv1.CertificateRequest)
+			if value := issuerIndexValue(cr.Namespace, cr.Status.Issuer); value != "" {
+				return []string{value}
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&networkingv1.CertificateRequest{}).
-		Named("certificaterequest").
-		Complete(r)
+		Owns(&certmanagerv1.Certificate{}).
+		Watches(&certmanagerv1.Issuer{}, handler.EnqueueRequestsFromMapFunc(r.requestsForIssuer)).
+		Watches(&certmanagerv1.ClusterIssuer{}, handler.EnqueueRequestsFromMapFunc(r.requestsForClusterIssuer)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.requestForReplica)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.requestsForNamespace)).
+		Named("certificaterequest")
+
+	if r.VaultWatcher != nil {
+		builder = builder.WatchesRawSource(r.VaultWatcher.CertificateRequestSource())
+	}
+
+	return builder.Complete(r)
 }
 
 func int32Ptr(i int32) *int32 {
@@ -17,14 +17,24 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // TestBuildCertificate validates cert-manager Certificate creation logic
@@ -42,6 +52,7 @@ func TestBuildCertificate(t *testing.T) {
 		name     string
 		cr       *networkingv1.CertificateRequest
 		fqdn     string
+		entry    *utils.DomainEntry
 		wantName string
 		wantKind string
 	}{
@@ -81,11 +92,51 @@ func TestBuildCertificate(t *testing.T) {
 			wantName: testLetsEncrypt,
 			wantKind: "Issuer",
 		},
+		{
+			name: "domain entry issuer",
+			cr: &networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cert",
+					Namespace: testNamespace,
+				},
+				Spec: networkingv1.CertificateRequestSpec{
+					SecretName: testSecretName,
+					DomainKey:  testDomainKey,
+					Subdomain:  testSubdomain,
+				},
+			},
+			fqdn:     testFQDN,
+			entry:    &utils.DomainEntry{Domain: "example.com", IssuerName: testLetsEncrypt, IssuerKind: "Issuer"},
+			wantName: testLetsEncrypt,
+			wantKind: "Issuer",
+		},
+		{
+			name: "request overrides domain entry",
+			cr: &networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cert",
+					Namespace: testNamespace,
+				},
+				Spec: networkingv1.CertificateRequestSpec{
+					SecretName: testSecretName,
+					DomainKey:  testDomainKey,
+					Subdomain:  testSubdomain,
+					IssuerName: testIssuerName,
+				},
+			},
+			fqdn:     testFQDN,
+			entry:    &utils.DomainEntry{Domain: "example.com", IssuerName: testLetsEncrypt, IssuerKind: "Issuer"},
+			wantName: testIssuerName,
+			wantKind: "Issuer",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cert := reconciler.buildCertificate(tt.cr, tt.fqdn)
+			cert, err := reconciler.buildCertificate(tt.cr, tt.fqdn, nil, tt.entry)
+			if err != nil {
+				t.Fatalf("buildCertificate returned error: %v", err)
+			}
 
 			// Validate cert-manager API structure
 			if cert == nil {
@@ -124,6 +175,211 @@ func TestBuildCertificate(t *testing.T) {
 	}
 }
 
+// TestCertificateRequestGetFQDN validates domain lookup through the injected sources
+func TestCertificateRequestGetFQDN(t *testing.T) {
+	reconciler := &CertificateRequestReconciler{Domains: testDomainSources()}
+
+	tests := []struct {
+		name      string
+		spec      networkingv1.CertificateRequestSpec
+		want      string
+		wantError bool
+	}{
+		{
+			name: "default source with subdomain",
+			spec: networkingv1.CertificateRequestSpec{DomainKey: testDomainKey, Subdomain: testSubdomain},
+			want: testFQDN,
+		},
+		{
+			name: "apex domain",
+			spec: networkingv1.CertificateRequestSpec{DomainKey: testDomainKey},
+			want: "example.com",
+		},
+		{
+			name: "configmap source",
+			spec: networkingv1.CertificateRequestSpec{
+				DomainKey:    testDomainKey,
+				Subdomain:    testSubdomain,
+				DomainSource: &networkingv1.DomainSourceRef{Kind: "ConfigMap", Name: "domains"},
+			},
+			want: testFQDN,
+		},
+		{
+			name: "unregistered source",
+			spec: networkingv1.CertificateRequestSpec{
+				DomainKey:    testDomainKey,
+				DomainSource: &networkingv1.DomainSourceRef{Kind: "Secret"},
+			},
+			wantError: true,
+		},
+		{
+			name:      "unknown key",
+			spec:      networkingv1.CertificateRequestSpec{DomainKey: "missingDomain"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+				Spec:       tt.spec,
+			}
+
+			fqdn, _, err := reconciler.getFQDN(context.Background(), cr)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error, got FQDN %q", fqdn)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fqdn != tt.want {
+				t.Errorf("FQDN = %v, want %v", fqdn, tt.want)
+			}
+		})
+	}
+}
+
+// TestCertificateRequestSecretDomainSource validates a request can only name a Secret as its
+// domain source when the Secret opted in, while the operator's configured Secret is always read
+func TestCertificateRequestSecretDomainSource(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	domains := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "domains", Namespace: testNamespace},
+		Data:       map[string][]byte{testDomainKey: []byte("example.com")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(domains).Build()
+	sources := utils.NewDomainSources(fakeClient, nil, utils.DomainSourceSecret, testNamespace+"/domains")
+	reconciler := &CertificateRequestReconciler{Domains: sources}
+
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+		Spec: networkingv1.CertificateRequestSpec{
+			DomainKey:    testDomainKey,
+			DomainSource: &networkingv1.DomainSourceRef{Kind: utils.DomainSourceSecret, Name: "domains"},
+		},
+	}
+	if fqdn, _, err := reconciler.getFQDN(context.Background(), cr); err == nil {
+		t.Errorf("Expected error for a Secret without %s, got FQDN %q", utils.DomainSourceAnnotation, fqdn)
+	}
+
+	// The operator's own domain source needs no opt-in
+	cr.Spec.DomainSource = nil
+	if fqdn, _, err := reconciler.getFQDN(context.Background(), cr); err != nil || fqdn != "example.com" {
+		t.Errorf("FQDN = %q, %v, want the value of the operator's Secret", fqdn, err)
+	}
+
+	domains.Annotations = map[string]string{utils.DomainSourceAnnotation: "true"}
+	if err := fakeClient.Update(context.Background(), domains); err != nil {
+		t.Fatal(err)
+	}
+	cr.Spec.DomainSource = &networkingv1.DomainSourceRef{Kind: utils.DomainSourceSecret, Name: "domains"}
+	if _, _, err := reconciler.getFQDN(context.Background(), cr); err != nil {
+		t.Errorf("Unexpected error for an annotated Secret: %v", err)
+	}
+}
+
+// TestCertificateRequestResolveDNSNames validates DNS names are resolved per domain key and deduplicated
+func TestCertificateRequestResolveDNSNames(t *testing.T) {
+	static := utils.StaticDomainSource{testDomainKey: "example.com", "labDomain": "lab.example.net"}
+	reconciler := &CertificateRequestReconciler{Domains: &utils.DomainSources{
+		DefaultKind: utils.DomainSourceVault,
+		Sources:     map[string]utils.DomainSource{utils.DomainSourceVault: static},
+	}}
+
+	tests := []struct {
+		name      string
+		dnsNames  []networkingv1.CertificateDNSName
+		want      []string
+		wantError bool
+	}{
+		{
+			name: "no extra names",
+			want: []string{testFQDN},
+		},
+		{
+			name: "subdomains, wildcards and apex on the same domain",
+			dnsNames: []networkingv1.CertificateDNSName{
+				{Subdomain: "api"},
+				{Subdomain: "*.apps"},
+				{},
+			},
+			want: []string{testFQDN, "api.example.com", "*.apps.example.com", "example.com"},
+		},
+		{
+			name: "other domain key",
+			dnsNames: []networkingv1.CertificateDNSName{
+				{DomainKey: "labDomain", Subdomain: "*"},
+				{DomainKey: "labDomain"},
+			},
+			want: []string{testFQDN, "*.lab.example.net", "lab.example.net"},
+		},
+		{
+			name: "duplicates are dropped",
+			dnsNames: []networkingv1.CertificateDNSName{
+				{Subdomain: testSubdomain},
+				{DomainKey: testDomainKey, Subdomain: "api"},
+				{Subdomain: "api"},
+			},
+			want: []string{testFQDN, "api.example.com"},
+		},
+		{
+			name:      "unknown key",
+			dnsNames:  []networkingv1.CertificateDNSName{{DomainKey: "missingDomain"}},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+				Spec: networkingv1.CertificateRequestSpec{
+					DomainKey: testDomainKey,
+					Subdomain: testSubdomain,
+					DNSNames:  tt.dnsNames,
+				},
+			}
+
+			ctx := context.Background()
+			fqdn, entry, err := reconciler.getFQDN(ctx, cr)
+			if err != nil {
+				t.Fatalf("getFQDN returned error: %v", err)
+			}
+			got, err := reconciler.resolveDNSNames(ctx, cr, fqdn, entry)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error, got DNS names %v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DNS names = %v, want %v", got, tt.want)
+			}
+
+			cert, err := reconciler.buildCertificate(cr, fqdn, got, entry)
+			if err != nil {
+				t.Fatalf("buildCertificate returned error: %v", err)
+			}
+			if cert.Spec.CommonName != testFQDN || !reflect.DeepEqual(cert.Spec.DNSNames, tt.want) {
+				t.Errorf("Certificate CommonName = %v, DNSNames = %v", cert.Spec.CommonName, cert.Spec.DNSNames)
+			}
+		})
+	}
+}
+
 // TestCertManagerObjectReference validates the IssuerRef structure
 func TestCertManagerObjectReference(t *testing.T) {
 	// Test that we can create an IssuerReference with expected fields
@@ -196,3 +452,191 @@ func TestInt32Ptr(t *testing.T) {
 		t.Errorf("int32Ptr = %v, want %v", *ptr, val)
 	}
 }
+
+// TestCertificateRequestReconcileStatus validates conditions follow the cert-manager Certificate
+func TestCertificateRequestReconcileStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace, Generation: 2},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName: testSecretName,
+			DomainKey:  testDomainKey,
+			Subdomain:  testSubdomain,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cr, testClusterIssuer()).
+		WithStatusSubresource(&networkingv1.CertificateRequest{}, &certmanagerv1.Certificate{}).
+		Build()
+	reconciler := &CertificateRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cr)}
+	key := client.ObjectKeyFromObject(cr)
+
+	// Certificate created but not yet issued
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	var got networkingv1.CertificateRequest
+	if err := fakeClient.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Ready || got.Status.FQDN != testFQDN || got.Status.ObservedGeneration != 2 {
+		t.Errorf("status = %+v, want not ready with FQDN %s at generation 2", got.Status, testFQDN)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, networkingv1.ConditionVaultResolved) {
+		t.Error("VaultResolved should be True")
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady); cond == nil ||
+		cond.Status != metav1.ConditionUnknown || cond.Reason != networkingv1.ReasonIssuing {
+		t.Errorf("Ready condition = %+v, want Unknown/%s", cond, networkingv1.ReasonIssuing)
+	}
+
+	// cert-manager issues the certificate
+	var cert certmanagerv1.Certificate
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "test-cert-certificate"}, &cert); err != nil {
+		t.Fatal(err)
+	}
+	notAfter := metav1.NewTime(time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second))
+	cert.Status = certmanagerv1.CertificateStatus{
+		NotAfter: &notAfter,
+		Conditions: []certmanagerv1.CertificateCondition{
+			{Type: certmanagerv1.CertificateConditionReady, Status: cmmeta.ConditionTrue, Message: "Certificate is up to date"},
+		},
+	}
+	if err := fakeClient.Status().Update(ctx, &cert); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := fakeClient.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Status.Ready || !meta.IsStatusConditionTrue(got.Status.Conditions, networkingv1.ConditionCertificateIssued) {
+		t.Errorf("status = %+v, want ready and issued", got.Status)
+	}
+	if got.Status.NotAfter == nil || !got.Status.NotAfter.Equal(&notAfter) {
+		t.Errorf("NotAfter = %v, want %v", got.Status.NotAfter, notAfter)
+	}
+
+	// A failed domain lookup marks the request not ready
+	got.Spec.DomainKey = "missingDomain"
+	got.Generation = 3
+	if err := fakeClient.Update(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err == nil {
+		t.Fatal("Expected error for unknown domain key")
+	}
+	if err := fakeClient.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionVaultResolved); cond == nil ||
+		cond.Status != metav1.ConditionFalse || cond.Reason != networkingv1.ReasonDomainLookupFailed {
+		t.Errorf("VaultResolved condition = %+v, want False/%s", cond, networkingv1.ReasonDomainLookupFailed)
+	}
+	if got.Status.Ready {
+		t.Error("Ready should be false after a failed domain lookup")
+	}
+}
+
+// TestCertificateRequestDeletionPolicy validates the finalizer removes the Certificate and,
+// with the Delete policy, only the Secret issued for the request
+func TestCertificateRequestDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         networkingv1.DeletionPolicy
+		secretCertName string
+		wantSecret     bool
+	}{
+		{
+			name:           "retain",
+			policy:         networkingv1.DeletionPolicyRetain,
+			secretCertName: "test-cert-certificate",
+			wantSecret:     true,
+		},
+		{
+			name:           "delete",
+			policy:         networkingv1.DeletionPolicyDelete,
+			secretCertName: "test-cert-certificate",
+			wantSecret:     false,
+		},
+		{
+			name:           "delete keeps a Secret issued for another Certificate",
+			policy:         networkingv1.DeletionPolicyDelete,
+			secretCertName: "other-certificate",
+			wantSecret:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = networkingv1.AddToScheme(scheme)
+			_ = certmanagerv1.AddToScheme(scheme)
+			_ = corev1.AddToScheme(scheme)
+
+			cr := &networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+				Spec: networkingv1.CertificateRequestSpec{
+					SecretName:     testSecretName,
+					DomainKey:      testDomainKey,
+					DeletionPolicy: tt.policy,
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        testSecretName,
+					Namespace:   testNamespace,
+					Annotations: map[string]string{certmanagerv1.CertificateNameKey: tt.secretCertName},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(cr, secret, testClusterIssuer()).
+				WithStatusSubresource(&networkingv1.CertificateRequest{}).
+				Build()
+			reconciler := &CertificateRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+			ctx := context.Background()
+			key := client.ObjectKeyFromObject(cr)
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile returned error: %v", err)
+			}
+			if err := fakeClient.Get(ctx, key, cr); err != nil {
+				t.Fatal(err)
+			}
+			if !controllerutil.ContainsFinalizer(cr, certificateRequestFinalizer) {
+				t.Fatal("expected the finalizer to be added")
+			}
+
+			if err := fakeClient.Delete(ctx, cr); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile returned error: %v", err)
+			}
+
+			if err := fakeClient.Get(ctx, key, cr); !errors.IsNotFound(err) {
+				t.Errorf("expected the CertificateRequest to be gone, got %v", err)
+			}
+			var cert certmanagerv1.Certificate
+			certKey := client.ObjectKey{Namespace: testNamespace, Name: "test-cert-certificate"}
+			if err := fakeClient.Get(ctx, certKey, &cert); !errors.IsNotFound(err) {
+				t.Errorf("expected the Certificate to be deleted, got %v", err)
+			}
+			err := fakeClient.Get(ctx, client.ObjectKeyFromObject(secret), &corev1.Secret{})
+			if tt.wantSecret && err != nil {
+				t.Errorf("expected the Secret to be kept, got %v", err)
+			}
+			if !tt.wantSecret && !errors.IsNotFound(err) {
+				t.Errorf("expected the Secret to be deleted, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+const (
+	defaultVaultPath = "kv/data/domains"
+
+	// vaultDomainIndex indexes requests by the Vault connection, path and domain key they read
+	vaultDomainIndex = "spec.vaultConnection/spec.vaultPath/spec.domainKey"
+)
+
+// defaultDomainSources is used by reconcilers that were not given any DomainSources
+var defaultDomainSources = &utils.DomainSources{
+	DefaultKind: utils.DomainSourceVault,
+	Sources: map[string]utils.DomainSource{
+		utils.DomainSourceVault: utils.VaultDomainSource{},
+	},
+}
+
+// domainRequest holds the fields of a request that select where its domain is read from
+type domainRequest struct {
+	Namespace       string
+	Source          *networkingv1.DomainSourceRef
+	VaultConnection *networkingv1.VaultConnectionReference
+	VaultPath       string
+	DomainKey       string
+}
+
+// certificateDomainRequest returns the domain lookup fields of a CertificateRequest
+func certificateDomainRequest(cr *networkingv1.CertificateRequest) domainRequest {
+	return domainRequest{
+		Namespace:       cr.Namespace,
+		Source:          cr.Spec.DomainSource,
+		VaultConnection: cr.Spec.VaultConnection,
+		VaultPath:       cr.Spec.VaultPath,
+		DomainKey:       cr.Spec.DomainKey,
+	}
+}
+
+// certificateDomainRequests returns one domain request per distinct domain key of a
+// CertificateRequest, starting with spec.domainKey
+func certificateDomainRequests(cr *networkingv1.CertificateRequest) []domainRequest {
+	req := certificateDomainRequest(cr)
+	reqs := []domainRequest{req}
+	seen := map[string]bool{req.DomainKey: true}
+	for _, dnsName := range cr.Spec.DNSNames {
+		if dnsName.DomainKey == "" || seen[dnsName.DomainKey] {
+			continue
+		}
+		seen[dnsName.DomainKey] = true
+		reqs = append(reqs, req.withKey(dnsName.DomainKey))
+	}
+	return reqs
+}
+
+// ingressDomainRequest returns the domain lookup fields of an IngressRequest
+func ingressDomainRequest(ir *networkingv1.IngressRequest) domainRequest {
+	return domainRequest{
+		Namespace:       ir.Namespace,
+		Source:          ir.Spec.DomainSource,
+		VaultConnection: ir.Spec.VaultConnection,
+		VaultPath:       ir.Spec.VaultPath,
+		DomainKey:       ir.Spec.DomainKey,
+	}
+}
+
+// withKey returns a copy of req that looks up key
+func (req domainRequest) withKey(key string) domainRequest {
+	req.DomainKey = key
+	return req
+}
+
+// kind returns the domain source kind of req, falling back to the operator's default
+func (req domainRequest) kind(sources *utils.DomainSources) string {
+	if req.Source != nil {
+		return req.Source.Kind
+	}
+	return sources.DefaultKind
+}
+
+// resolveDomain looks up the request's domain entry in the domain source it selects,
+// falling back to the operator's default source when it selects none
+func resolveDomain(ctx context.Context, sources *utils.DomainSources, req domainRequest) (*utils.DomainEntry, error) {
+	if sources == nil {
+		sources = defaultDomainSources
+	}
+
+	kind := req.kind(sources)
+	source, err := sources.Get(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := utils.DomainLookup{Namespace: req.Namespace, Key: req.DomainKey}
+	switch {
+	case kind == utils.DomainSourceVault:
+		lookup.Path = effectiveVaultPath(req.VaultPath)
+		lookup.Connection = vaultConnectionKey(req.Namespace, req.VaultConnection)
+	case req.Source != nil && req.Source.Name != "" && kind != utils.DomainSourceFile:
+		lookup.Path = req.Source.Name
+		lookup.Requested = true
+	default:
+		lookup.Path = sources.DefaultPath
+	}
+
+	if lookup.Path == "" {
+		return nil, fmt.Errorf("no location configured for %s domain source", kind)
+	}
+
+	entry, err := source.GetDomain(ctx, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain from %s: %w", kind, err)
+	}
+
+	return entry, nil
+}
+
+// effectiveVaultPath applies the default Vault path
+func effectiveVaultPath(vaultPath string) string {
+	if vaultPath == "" {
+		return defaultVaultPath
+	}
+	return vaultPath
+}
+
+// VaultSecretRef identifies a Vault KV secret on a connection.
+// Connection is a key built by vaultConnectionKey; empty selects the default connection.
+type VaultSecretRef struct {
+	Connection string
+	Path       string
+}
+
+// vaultLookup returns the Vault secret a request reads its domain from,
+// or false when the request uses another domain source
+func vaultLookup(sources *utils.DomainSources, req domainRequest) (VaultSecretRef, bool) {
+	if sources == nil {
+		sources = defaultDomainSources
+	}
+
+	if req.kind(sources) != utils.DomainSourceVault {
+		return VaultSecretRef{}, false
+	}
+	return VaultSecretRef{
+		Connection: vaultConnectionKey(req.Namespace, req.VaultConnection),
+		Path:       effectiveVaultPath(req.VaultPath),
+	}, true
+}
+
+// vaultDomainIndexValue builds the vaultDomainIndex value for a secret and key
+func vaultDomainIndexValue(ref VaultSecretRef, key string) string {
+	return ref.Connection + "#" + ref.Path + "#" + key
+}
+
+// vaultDomainIndexValues returns the vaultDomainIndex values of a request's domain lookups
+func vaultDomainIndexValues(sources *utils.DomainSources, reqs ...domainRequest) []string {
+	var values []string
+	for _, req := range reqs {
+		if ref, ok := vaultLookup(sources, req); ok {
+			values = append(values, vaultDomainIndexValue(ref, req.DomainKey))
+		}
+	}
+	return values
+}
+
+// joinDomain prepends subdomain to domain; an empty subdomain names the domain itself
+func joinDomain(subdomain, domain string) string {
+	if subdomain == "" {
+		return domain
+	}
+	return subdomain + "." + domain
+}
+
+// firstNonEmpty returns the first non-empty value, used to layer request, domain and operator defaults
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -3,13 +3,23 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	traefikv1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -21,19 +31,36 @@ import (
 const (
 	defaultEntrypoint = "web"
 	routeKind         = "Rule"
+
+	// serviceNameIndex indexes IngressRequests by their target Service
+	serviceNameIndex = "spec.serviceName"
+	// middlewareIndex indexes IngressRequests by the "namespace/name" of their middlewares
+	middlewareIndex = "spec.middlewares"
 )
 
 // IngressRequestReconciler reconciles a IngressRequest object
 type IngressRequestReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Domains resolves domain keys; Vault is used when nil
+	Domains *utils.DomainSources
+	// VaultWatcher enqueues requests whose Vault domain changed (optional)
+	VaultWatcher *VaultWatcher
+	// Recorder emits events when a generated IngressRoute drifted (optional)
+	Recorder events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=networking.alm.homelab,resources=ingressrequests,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.alm.homelab,resources=ingressrequests/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=networking.alm.homelab,resources=ingressrequests/finalizers,verbs=update
-// +kubebuilder:rbac:groups=traefik.io,resources=ingressroutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=traefik.io,resources=ingressroutes;ingressroutetcps;ingressrouteudps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=traefik.io,resources=traefikservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=traefik.io,resources=middlewares,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get
+// +kubebuilder:rbac:groups=networking.alm.homelab,resources=vaultconnections;clustervaultconnections,verbs=get
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 func (r *IngressRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -48,54 +75,117 @@ func (r *IngressRequestReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
-	// Fetch domain from Vault and construct FQDN
-	fqdn, err := r.getFQDN(&ir)
+	original := ir.Status.DeepCopy()
+
+	// Fetch domain from the domain source and construct FQDN
+	fqdn, entry, err := r.getFQDN(ctx, &ir)
 	if err != nil {
 		logger.Error(err, "failed to construct FQDN")
+		setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionVaultResolved,
+			metav1.ConditionFalse, networkingv1.ReasonDomainLookupFailed, err.Error())
+		r.setNotReady(&ir, networkingv1.ReasonDomainLookupFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &ir, original, err)
+	}
+	setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionVaultResolved,
+		metav1.ConditionTrue, networkingv1.ReasonDomainResolved, fmt.Sprintf("Domain key %s resolved to %s", ir.Spec.DomainKey, entry.Domain))
+	ir.Status.FQDN = fqdn
+
+	// Look up the Services the request sends traffic to, filling in ports it omits
+	ports, services, err := r.resolveServices(ctx, &ir)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Build the IngressRoute
-	route := r.buildIngressRoute(&ir, fqdn)
-	if err := ctrl.SetControllerReference(&ir, route, r.Scheme); err != nil {
-		logger.Error(err, "failed to set controller reference")
+	// Refuse to take over TraefikServices created by someone else
+	conflict, err := r.traefikServiceConflict(ctx, &ir, ports)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if conflict != "" {
+		r.setNotReady(&ir, networkingv1.ReasonTraefikServiceConflict, conflict)
+		return ctrl.Result{}, r.updateStatus(ctx, &ir, original)
+	}
 
-	// Create or update the IngressRoute
-	if err := r.createOrUpdateIngressRoute(ctx, route); err != nil {
-		logger.Error(err, "failed to create or update IngressRoute")
+	// Likewise for the Certificate of tls.autoCertificate
+	conflict, err = r.autoCertificateConflict(ctx, &ir)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if conflict != "" {
+		r.setNotReady(&ir, networkingv1.ReasonCertificateConflict, conflict)
+		return ctrl.Result{}, r.updateStatus(ctx, &ir, original)
+	}
+
+	// Apply the TraefikServices for weighted and mirrored backends before the route pointing at them
+	if err := r.syncTraefikServices(ctx, &ir, ports); err != nil {
+		logger.Error(err, "failed to sync TraefikServices")
+		r.setNotReady(&ir, networkingv1.ReasonTraefikServiceSyncFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &ir, original, err)
+	}
 
-	logger.Info("Successfully reconciled IngressRoute", "fqdn", fqdn)
+	// Provision the certificate of tls.autoCertificate; the route only switches to TLS once it is issued
+	tlsSecret, err := r.syncAutoCertificate(ctx, &ir, fqdn, entry)
+	if err != nil {
+		logger.Error(err, "failed to sync Certificate")
+		r.setNotReady(&ir, networkingv1.ReasonCertificateSyncFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &ir, original, err)
+	}
 
-	// Update status
-	return r.updateStatus(ctx, &ir, fqdn)
-}
+	// Build and apply the route of the request's protocol
+	middlewares, err := r.syncRoute(ctx, withAutoCertificate(&ir, tlsSecret), ports, fqdn, entry)
+	if err != nil {
+		logger.Error(err, "failed to sync route")
+		r.setNotReady(&ir, networkingv1.ReasonIngressRouteSyncFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &ir, original, err)
+	}
+
+	logger.Info("Successfully reconciled route", "fqdn", fqdn, "protocol", ingressProtocol(&ir))
 
-// getFQDN constructs the FQDN by fetching the domain from Vault
-func (r *IngressRequestReconciler) getFQDN(ir *networkingv1.IngressRequest) (string, error) {
-	vaultPath := ir.Spec.VaultPath
-	if vaultPath == "" {
-		vaultPath = "kv/data/domains"
+	// Check the backends the route points at; the route is kept so it starts working once they exist
+	if err := r.checkBackends(ctx, &ir, middlewares, services); err != nil {
+		return ctrl.Result{}, err
+	}
+	if usesAutoCertificate(&ir) && tlsSecret == "" && meta.IsStatusConditionTrue(ir.Status.Conditions, networkingv1.ConditionReady) {
+		reason, message := networkingv1.ReasonIssuing, "Waiting for the certificate to be issued"
+		if issued := meta.FindStatusCondition(ir.Status.Conditions, networkingv1.ConditionCertificateIssued); issued != nil {
+			reason, message = issued.Reason, issued.Message
+		}
+		r.setNotReady(&ir, reason, message)
 	}
 
-	domain, err := utils.GetDomainFromVault(vaultPath, ir.Spec.DomainKey)
+	// Update status
+	return ctrl.Result{}, r.updateStatus(ctx, &ir, original)
+}
+
+// getFQDN constructs the FQDN by fetching the domain from the request's domain source.
+// It also returns the domain entry, whose defaults apply to fields the request leaves empty.
+func (r *IngressRequestReconciler) getFQDN(ctx context.Context,
+	ir *networkingv1.IngressRequest) (string, *utils.DomainEntry, error) {
+	entry, err := resolveDomain(ctx, r.Domains, ingressDomainRequest(ir))
 	if err != nil {
-		return "", fmt.Errorf("failed to get domain from Vault: %w", err)
+		return "", nil, err
 	}
 
-	return fmt.Sprintf("%s.%s", ir.Spec.Subdomain, domain), nil
+	return fmt.Sprintf("%s.%s", ir.Spec.Subdomain, entry.Domain), entry, nil
 }
 
-// buildIngressRoute constructs the desired IngressRoute resource
-func (r *IngressRequestReconciler) buildIngressRoute(ir *networkingv1.IngressRequest, fqdn string) *traefikv1alpha1.IngressRoute {
+// buildIngressRoute constructs the desired IngressRoute resource, routing to the Service ports resolved in ports.
+// Entrypoints, middlewares and the cert resolver left empty on the request fall back to the domain entry.
+func (r *IngressRequestReconciler) buildIngressRoute(ir *networkingv1.IngressRequest, ports servicePorts,
+	fqdn string, entry *utils.DomainEntry) *traefikv1alpha1.IngressRoute {
+	if entry == nil {
+		entry = &utils.DomainEntry{}
+	}
+
 	entrypoints := ir.Spec.Entrypoints
+	if len(entrypoints) == 0 {
+		entrypoints = entry.Entrypoints
+	}
 	if len(entrypoints) == 0 {
 		entrypoints = []string{defaultEntrypoint}
 	}
 
+	routes := effectiveRoutes(ir)
 	route := &traefikv1alpha1.IngressRoute{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ir.Name,
@@ -103,40 +193,189 @@ func (r *IngressRequestReconciler) buildIngressRoute(ir *networkingv1.IngressReq
 		},
 		Spec: traefikv1alpha1.IngressRouteSpec{
 			EntryPoints: entrypoints,
-			Routes: []traefikv1alpha1.Route{
-				{
-					Match:       fmt.Sprintf("Host(`%s`)", fqdn),
-					Kind:        routeKind,
-					Services:    r.buildServices(ir),
-					Middlewares: r.buildMiddlewares(ir),
-				},
-			},
+			Routes:      make([]traefikv1alpha1.Route, 0, len(routes)),
 		},
 	}
+	for _, rt := range routes {
+		middlewares := r.buildMiddlewares(ir, entry)
+		if len(rt.Middlewares) > 0 {
+			middlewares = convertMiddlewares(rt.Middlewares)
+		}
+		route.Spec.Routes = append(route.Spec.Routes, traefikv1alpha1.Route{
+			Match:       routeMatch(fqdn, rt),
+			Kind:        routeKind,
+			Priority:    rt.Priority,
+			Services:    r.buildServices(ir, rt, ports),
+			Middlewares: middlewares,
+		})
+	}
 
-	if ir.Spec.TLS != nil {
-		route.Spec.TLS = r.buildTLSConfig(ir.Spec.TLS)
+	if tlsSpec := effectiveTLSConfig(ir.Spec.TLS, entry); tlsSpec != nil {
+		route.Spec.TLS = r.buildTLSConfig(tlsSpec)
 	}
 
 	return route
 }
 
-// buildServices creates the service configuration for the IngressRoute
-func (r *IngressRequestReconciler) buildServices(ir *networkingv1.IngressRequest) []traefikv1alpha1.Service {
+// syncRoute applies the IngressRoute, IngressRouteTCP or IngressRouteUDP of ir and deletes the ones
+// of the protocols it no longer uses. It returns the middlewares the route references.
+func (r *IngressRequestReconciler) syncRoute(ctx context.Context, ir *networkingv1.IngressRequest, ports servicePorts,
+	fqdn string, entry *utils.DomainEntry) ([]traefikv1alpha1.MiddlewareRef, error) {
+	var middlewares []traefikv1alpha1.MiddlewareRef
+	switch ingressProtocol(ir) {
+	case networkingv1.IngressProtocolTCP:
+		route := r.buildIngressRouteTCP(ir, ports, fqdn)
+		if err := ctrl.SetControllerReference(ir, route, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set controller reference: %w", err)
+		}
+		if err := r.applyIngressRouteTCP(ctx, ir, route); err != nil {
+			return nil, err
+		}
+	case networkingv1.IngressProtocolUDP:
+		route := r.buildIngressRouteUDP(ir, ports)
+		if err := ctrl.SetControllerReference(ir, route, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set controller reference: %w", err)
+		}
+		if err := r.applyIngressRouteUDP(ctx, ir, route); err != nil {
+			return nil, err
+		}
+	default:
+		route := r.buildIngressRoute(ir, ports, fqdn, entry)
+		if err := ctrl.SetControllerReference(ir, route, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set controller reference: %w", err)
+		}
+		if err := r.applyIngressRoute(ctx, ir, route); err != nil {
+			return nil, err
+		}
+		middlewares = routeMiddlewares(route)
+	}
+
+	if err := r.deleteStaleRoutes(ctx, ir); err != nil {
+		return nil, err
+	}
+	return middlewares, nil
+}
+
+// routeMiddlewares returns the distinct middlewares referenced by the routes of route
+func routeMiddlewares(route *traefikv1alpha1.IngressRoute) []traefikv1alpha1.MiddlewareRef {
+	var middlewares []traefikv1alpha1.MiddlewareRef
+	for _, rt := range route.Spec.Routes {
+		for _, mw := range rt.Middlewares {
+			if !slices.Contains(middlewares, mw) {
+				middlewares = append(middlewares, mw)
+			}
+		}
+	}
+	return middlewares
+}
+
+// effectiveTLSConfig applies the domain's cert resolver when the request
+// names neither a TLS secret nor a cert resolver. An automatic certificate is only used
+// once issued, as the secretName set by withAutoCertificate in place of the request's.
+func effectiveTLSConfig(tlsSpec *networkingv1.IngressTLSConfig, entry *utils.DomainEntry) *networkingv1.IngressTLSConfig {
+	if tlsSpec != nil && tlsSpec.AutoCertificate {
+		if tlsSpec.SecretName == "" {
+			return nil
+		}
+		return &networkingv1.IngressTLSConfig{SecretName: tlsSpec.SecretName}
+	}
+	if entry.CertResolver == "" || (tlsSpec != nil && (tlsSpec.SecretName != "" || tlsSpec.CertResolver != "")) {
+		return tlsSpec
+	}
+	return &networkingv1.IngressTLSConfig{CertResolver: entry.CertResolver}
+}
+
+// effectiveRoutes returns the request's routes with the service defaulted from the spec,
+// or a single catch-all route to spec.serviceName when it has none. Routes to the generated
+// TraefikService of the backends and mirror are left without a service name.
+func effectiveRoutes(ir *networkingv1.IngressRequest) []networkingv1.IngressRequestRoute {
+	routes := ir.Spec.Routes
+	if len(routes) == 0 {
+		routes = []networkingv1.IngressRequestRoute{{}}
+	}
+
+	effective := make([]networkingv1.IngressRequestRoute, 0, len(routes))
+	for _, rt := range routes {
+		if rt.ServiceName == "" && !usesTraefikService(ir) {
+			rt.ServiceName = ir.Spec.ServiceName
+			rt.ServicePort = firstNonEmpty(rt.ServicePort, ir.Spec.ServicePort)
+		}
+		effective = append(effective, rt)
+	}
+	return effective
+}
+
+// routeMatch builds the Traefik rule matching fqdn and the path, methods and headers of rt
+func routeMatch(fqdn string, rt networkingv1.IngressRequestRoute) string {
+	matchers := []string{fmt.Sprintf("Host(`%s`)", fqdn)}
+	switch {
+	case rt.Path != "":
+		matchers = append(matchers, fmt.Sprintf("Path(`%s`)", rt.Path))
+	case rt.PathPrefix != "":
+		matchers = append(matchers, fmt.Sprintf("PathPrefix(`%s`)", rt.PathPrefix))
+	}
+
+	if len(rt.Methods) > 0 {
+		methods := make([]string, 0, len(rt.Methods))
+		for _, method := range rt.Methods {
+			methods = append(methods, fmt.Sprintf("Method(`%s`)", method))
+		}
+		if len(methods) == 1 {
+			matchers = append(matchers, methods[0])
+		} else {
+			matchers = append(matchers, "("+strings.Join(methods, " || ")+")")
+		}
+	}
+
+	for _, header := range rt.Headers {
+		matchers = append(matchers, fmt.Sprintf("Header(`%s`, `%s`)", header.Name, header.Value))
+	}
+
+	return strings.Join(matchers, " && ")
+}
+
+// buildServices creates the service configuration for a route of the IngressRoute
+func (r *IngressRequestReconciler) buildServices(ir *networkingv1.IngressRequest, rt networkingv1.IngressRequestRoute,
+	ports servicePorts) []traefikv1alpha1.Service {
+	if rt.ServiceName == "" {
+		return []traefikv1alpha1.Service{
+			{
+				LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{Name: traefikServiceName(ir), Kind: traefikServiceKind},
+			},
+		}
+	}
+
 	return []traefikv1alpha1.Service{
 		{
 			LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
-				Name: ir.Spec.ServiceName,
-				Port: intstr.FromString(ir.Spec.ServicePort),
+				Name: rt.ServiceName,
+				Port: ports.port(rt.ServiceName, rt.ServicePort),
 			},
 		},
 	}
 }
 
-// buildMiddlewares converts middleware references
-func (r *IngressRequestReconciler) buildMiddlewares(ir *networkingv1.IngressRequest) []traefikv1alpha1.MiddlewareRef {
-	middlewares := make([]traefikv1alpha1.MiddlewareRef, 0, len(ir.Spec.Middlewares))
-	for _, mw := range ir.Spec.Middlewares {
+// buildMiddlewares converts middleware references, using the domain's middlewares when the request has none
+func (r *IngressRequestReconciler) buildMiddlewares(ir *networkingv1.IngressRequest,
+	entry *utils.DomainEntry) []traefikv1alpha1.MiddlewareRef {
+	if len(ir.Spec.Middlewares) == 0 && entry != nil {
+		middlewares := make([]traefikv1alpha1.MiddlewareRef, 0, len(entry.Middlewares))
+		for _, mw := range entry.Middlewares {
+			middlewares = append(middlewares, traefikv1alpha1.MiddlewareRef{
+				Name:      mw.Name,
+				Namespace: firstNonEmpty(mw.Namespace, ir.Namespace),
+			})
+		}
+		return middlewares
+	}
+
+	return convertMiddlewares(ir.Spec.Middlewares)
+}
+
+// convertMiddlewares converts request middleware references to Traefik's
+func convertMiddlewares(refs []networkingv1.MiddlewareRef) []traefikv1alpha1.MiddlewareRef {
+	middlewares := make([]traefikv1alpha1.MiddlewareRef, 0, len(refs))
+	for _, mw := range refs {
 		middlewares = append(middlewares, traefikv1alpha1.MiddlewareRef{
 			Name:      mw.Name,
 			Namespace: mw.Namespace,
@@ -160,49 +399,349 @@ func (r *IngressRequestReconciler) buildTLSConfig(tlsSpec *networkingv1.IngressT
 	return tlsConfig
 }
 
-// createOrUpdateIngressRoute creates or updates the IngressRoute resource
-func (r *IngressRequestReconciler) createOrUpdateIngressRoute(ctx context.Context, route *traefikv1alpha1.IngressRoute) error {
-	logger := log.FromContext(ctx)
+// applyIngressRoute server-side applies the IngressRoute, reporting manual changes to it as drift
+func (r *IngressRequestReconciler) applyIngressRoute(ctx context.Context, ir *networkingv1.IngressRequest,
+	route *traefikv1alpha1.IngressRoute) error {
+	return r.applyGenerated(ctx, ir, route, &traefikv1alpha1.IngressRoute{}, "IngressRoute",
+		func(obj client.Object) interface{} { return obj.(*traefikv1alpha1.IngressRoute).Spec })
+}
 
-	var existing traefikv1alpha1.IngressRoute
-	err := r.Get(ctx, client.ObjectKeyFromObject(route), &existing)
+// applyGenerated server-side applies an object generated for ir, reporting manual changes to it as drift.
+// live receives the existing object and spec returns the spec of either.
+func (r *IngressRequestReconciler) applyGenerated(ctx context.Context, ir *networkingv1.IngressRequest,
+	obj, live client.Object, kind string, spec func(client.Object) interface{}) error {
+	hash, err := specHash(spec(obj))
+	if err != nil {
+		return err
+	}
+	setSpecHash(obj, hash)
 
-	if errors.IsNotFound(err) {
-		if err := r.Create(ctx, route); err != nil {
-			return fmt.Errorf("failed to create IngressRoute: %w", err)
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), live); err == nil {
+		if specDrifted(live, hash, spec(obj), spec(live)) {
+			log.FromContext(ctx).Info("Reverting drifted "+kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+			recordDrift(r.Recorder, ir, live, kind)
 		}
-		logger.Info("Created IngressRoute", "name", route.Name, "namespace", route.Namespace)
-		return nil
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get existing %s: %w", kind, err)
 	}
 
-	if err != nil {
-		return fmt.Errorf("failed to get existing IngressRoute: %w", err)
+	if err := applyObject(ctx, r.Client, r.Scheme, obj); err != nil {
+		return fmt.Errorf("failed to apply %s: %w", kind, err)
 	}
 
-	// Update existing IngressRoute
-	route.ResourceVersion = existing.ResourceVersion
-	if err := r.Update(ctx, route); err != nil {
-		return fmt.Errorf("failed to update IngressRoute: %w", err)
+	log.FromContext(ctx).Info("Applied "+kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+	return nil
+}
+
+// checkBackends sets the ServiceResolved, MiddlewaresResolved and Ready conditions for the middlewares
+// of the route, given the outcome of resolveServices
+func (r *IngressRequestReconciler) checkBackends(ctx context.Context, ir *networkingv1.IngressRequest,
+	middlewares []traefikv1alpha1.MiddlewareRef, services serviceCheck) error {
+	serviceReason, serviceMessage := services.reason, services.message
+	serviceFound := serviceReason == networkingv1.ReasonServiceFound
+	setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionServiceResolved,
+		conditionStatus(serviceFound), serviceReason, serviceMessage)
+
+	middlewareReason, middlewareMessage, err := r.checkMiddlewares(ctx, middlewares)
+	if err != nil {
+		return err
+	}
+	middlewaresFound := middlewareReason == networkingv1.ReasonMiddlewaresFound
+	setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionMiddlewaresResolved,
+		conditionStatus(middlewaresFound), middlewareReason, middlewareMessage)
+
+	switch {
+	case !serviceFound:
+		r.setNotReady(ir, serviceReason, serviceMessage)
+	case !middlewaresFound:
+		r.setNotReady(ir, middlewareReason, middlewareMessage)
+	default:
+		setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionReady,
+			metav1.ConditionTrue, networkingv1.ReasonRouteReady, fmt.Sprintf("Routing %s to %s", ir.Status.FQDN, strings.Join(routeServiceNames(ir), ", ")))
 	}
 
-	logger.Info("Updated IngressRoute", "name", route.Name, "namespace", route.Namespace)
 	return nil
 }
 
-// updateStatus updates the IngressRequest status
-func (r *IngressRequestReconciler) updateStatus(ctx context.Context, ir *networkingv1.IngressRequest, fqdn string) (ctrl.Result, error) {
-	ir.Status.FQDN = fqdn
+// serviceCheck is the ServiceResolved reason and message for the Services of a request
+type serviceCheck struct {
+	reason  string
+	message string
+}
+
+// serviceTarget is a Service port the request sends traffic to, as written in the request
+type serviceTarget struct {
+	name string
+	port string
+}
+
+// servicePorts maps the targets of a request to the ports they resolved to
+type servicePorts map[serviceTarget]string
+
+// port returns Traefik's port for the Service name and the requested port.
+// Numbers are Service ports, anything else a port name.
+func (p servicePorts) port(name, port string) intstr.IntOrString {
+	if resolved, ok := p[serviceTarget{name: name, port: port}]; ok {
+		port = resolved
+	}
+	return intstr.Parse(port)
+}
+
+// serviceTargets returns the distinct Services and ports of the routes, backends and mirror of ir
+func serviceTargets(ir *networkingv1.IngressRequest) []serviceTarget {
+	var targets []serviceTarget
+	add := func(name, port string) {
+		target := serviceTarget{name: name, port: port}
+		if name != "" && !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+
+	for _, rt := range effectiveRoutes(ir) {
+		add(rt.ServiceName, rt.ServicePort)
+	}
+	for _, backend := range ir.Spec.Backends {
+		add(backend.ServiceName, backend.ServicePort)
+	}
+	if ir.Spec.Mirror != nil {
+		if len(ir.Spec.Backends) == 0 {
+			add(ir.Spec.ServiceName, ir.Spec.ServicePort)
+		}
+		add(ir.Spec.Mirror.ServiceName, ir.Spec.Mirror.ServicePort)
+	}
+	return targets
+}
+
+// resolveServices verifies every target Service of the request exists and exposes its port.
+// It returns the ports the targets resolved to, filling in the port of single-port Services
+// the request omits it for, and the condition reason and message; errors are only returned for failed lookups.
+func (r *IngressRequestReconciler) resolveServices(ctx context.Context,
+	ir *networkingv1.IngressRequest) (servicePorts, serviceCheck, error) {
+	ports := servicePorts{}
+	result := serviceCheck{reason: networkingv1.ReasonServiceFound}
+	var messages []string
+	for _, target := range serviceTargets(ir) {
+		key := client.ObjectKey{Namespace: ir.Namespace, Name: target.name}
+		port, check, err := r.checkService(ctx, key, target.port)
+		if err != nil {
+			return nil, serviceCheck{}, err
+		}
+		ports[target] = port
+
+		// The first failure is reported; the other targets are still resolved
+		if check.reason != networkingv1.ReasonServiceFound {
+			if result.reason == networkingv1.ReasonServiceFound {
+				result = check
+			}
+			continue
+		}
+		messages = append(messages, check.message)
+	}
+
+	if result.reason == networkingv1.ReasonServiceFound {
+		result.message = strings.Join(messages, "; ")
+	}
+	return ports, result, nil
+}
+
+// checkService verifies the Service at key exists and exposes port, given as a number or name.
+// An empty port resolves to the only port of the Service. It returns the port to route to
+// and the condition reason and message; errors are only returned for failed lookups.
+func (r *IngressRequestReconciler) checkService(ctx context.Context, key client.ObjectKey,
+	port string) (string, serviceCheck, error) {
+	var svc corev1.Service
+	if err := r.Get(ctx, key, &svc); err != nil {
+		if errors.IsNotFound(err) {
+			return port, serviceCheck{networkingv1.ReasonServiceNotFound, fmt.Sprintf("Service %s not found", key)}, nil
+		}
+		return "", serviceCheck{}, fmt.Errorf("failed to get Service %s: %w", key, err)
+	}
+
+	if port == "" {
+		if len(svc.Spec.Ports) != 1 {
+			return port, serviceCheck{networkingv1.ReasonServicePortRequired,
+				fmt.Sprintf("Service %s has %d ports (%s), servicePort must name one",
+					key, len(svc.Spec.Ports), servicePortList(&svc))}, nil
+		}
+		port = strconv.Itoa(int(svc.Spec.Ports[0].Port))
+	}
+
+	if !serviceHasPort(&svc, port) {
+		return port, serviceCheck{networkingv1.ReasonServicePortNotFound,
+			fmt.Sprintf("Service %s does not expose port %s (ports: %s)", key, port, servicePortList(&svc))}, nil
+	}
+
+	return port, serviceCheck{networkingv1.ReasonServiceFound, fmt.Sprintf("Service %s exposes port %s", key, port)}, nil
+}
+
+// servicePortList describes the ports of svc for status messages
+func servicePortList(svc *corev1.Service) string {
+	if len(svc.Spec.Ports) == 0 {
+		return "none"
+	}
+	ports := make([]string, 0, len(svc.Spec.Ports))
+	for _, p := range svc.Spec.Ports {
+		if p.Name != "" {
+			ports = append(ports, fmt.Sprintf("%s/%d", p.Name, p.Port))
+		} else {
+			ports = append(ports, strconv.Itoa(int(p.Port)))
+		}
+	}
+	return strings.Join(ports, ", ")
+}
+
+// routeServiceNames returns the distinct Services the request sends traffic to
+func routeServiceNames(ir *networkingv1.IngressRequest) []string {
+	var names []string
+	for _, target := range serviceTargets(ir) {
+		if !slices.Contains(names, target.name) {
+			names = append(names, target.name)
+		}
+	}
+	return names
+}
+
+// serviceHasPort reports whether svc exposes port, given as a port number or name
+func serviceHasPort(svc *corev1.Service, port string) bool {
+	number, err := strconv.ParseInt(port, 10, 32)
+	for _, p := range svc.Spec.Ports {
+		if err == nil && int64(p.Port) == number {
+			return true
+		}
+		if err != nil && p.Name == port {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMiddlewares verifies every referenced Traefik Middleware exists.
+// It returns the condition reason and message; errors are only returned for failed lookups.
+func (r *IngressRequestReconciler) checkMiddlewares(ctx context.Context,
+	middlewares []traefikv1alpha1.MiddlewareRef) (string, string, error) {
+	var missing []string
+	for _, mw := range middlewares {
+		var middleware traefikv1alpha1.Middleware
+		key := client.ObjectKey{Namespace: mw.Namespace, Name: mw.Name}
+		if err := r.Get(ctx, key, &middleware); err != nil {
+			if errors.IsNotFound(err) {
+				missing = append(missing, key.String())
+				continue
+			}
+			return "", "", fmt.Errorf("failed to get Middleware %s: %w", key, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return networkingv1.ReasonMiddlewareNotFound,
+			fmt.Sprintf("Middlewares not found: %s", strings.Join(missing, ", ")), nil
+	}
+	return networkingv1.ReasonMiddlewaresFound, fmt.Sprintf("%d middlewares found", len(middlewares)), nil
+}
+
+// setNotReady marks the IngressRequest as not ready for reason
+func (r *IngressRequestReconciler) setNotReady(ir *networkingv1.IngressRequest, reason, message string) {
+	setCondition(&ir.Status.Conditions, ir.Generation, networkingv1.ConditionReady, metav1.ConditionFalse, reason, message)
+}
+
+// updateStatus writes the IngressRequest status when it differs from original
+func (r *IngressRequestReconciler) updateStatus(ctx context.Context, ir *networkingv1.IngressRequest,
+	original *networkingv1.IngressRequestStatus) error {
+	ir.Status.ObservedGeneration = ir.Generation
+	if equality.Semantic.DeepEqual(*original, ir.Status) {
+		return nil
+	}
 
 	if err := r.Status().Update(ctx, ir); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+		return fmt.Errorf("failed to update status: %w", err)
 	}
 
-	return ctrl.Result{}, nil
+	return nil
+}
+
+// updateStatusAfterError records a failed reconcile in the status and returns the original error
+func (r *IngressRequestReconciler) updateStatusAfterError(ctx context.Context, ir *networkingv1.IngressRequest,
+	original *networkingv1.IngressRequestStatus, err error) error {
+	if statusErr := r.updateStatus(ctx, ir, original); statusErr != nil {
+		log.FromContext(ctx).Error(statusErr, "failed to record error in status")
+	}
+	return err
+}
+
+// requestsForService maps a Service to the IngressRequests routing to it
+func (r *IngressRequestReconciler) requestsForService(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.requestsForIndex(ctx, obj.GetNamespace(), serviceNameIndex, obj.GetName())
+}
+
+// requestsForMiddleware maps a Middleware to the IngressRequests referencing it
+func (r *IngressRequestReconciler) requestsForMiddleware(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.requestsForIndex(ctx, "", middlewareIndex, obj.GetNamespace()+"/"+obj.GetName())
+}
+
+// requestsForIndex lists the IngressRequests indexed under value
+func (r *IngressRequestReconciler) requestsForIndex(ctx context.Context, namespace, index, value string) []reconcile.Request {
+	var list networkingv1.IngressRequestList
+	if err := r.List(ctx, &list, client.InNamespace(namespace), client.MatchingFields{index: value}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IngressRequests", "index", index, "value", value)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, ir := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ir)})
+	}
+	return requests
 }
 
 func (r *IngressRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &networkingv1.IngressRequest{}, vaultDomainIndex,
+		func(obj client.Object) []string {
+			ir := obj.(*networkingv1.IngressRequest)
+			return vaultDomainIndexValues(r.Domains, ingressDomainRequest(ir))
+		}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &networkingv1.IngressRequest{}, serviceNameIndex,
+		func(obj client.Object) []string {
+			return routeServiceNames(obj.(*networkingv1.IngressRequest))
+		}); err != nil {
+		return err
+	}
+
+	// Middlewares that only come from domain entries are not indexed; they are checked on every reconcile
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &networkingv1.IngressRequest{}, middlewareIndex,
+		func(obj client.Object) []string {
+			ir := obj.(*networkingv1.IngressRequest)
+			var values []string
+			refs := slices.Clone(ir.Spec.Middlewares)
+			for _, rt := range ir.Spec.Routes {
+				refs = append(refs, rt.Middlewares...)
+			}
+			for _, mw := range refs {
+				if value := mw.Namespace + "/" + mw.Name; !slices.Contains(values, value) {
+					values = append(values, value)
+				}
+			}
+			return values
+		}); err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&networkingv1.IngressRequest{}).
-		Named("ingressrequest").
-		Complete(r)
+		Owns(&traefikv1alpha1.IngressRoute{}).
+		Owns(&traefikv1alpha1.IngressRouteTCP{}).
+		Owns(&traefikv1alpha1.IngressRouteUDP{}).
+		Owns(&traefikv1alpha1.TraefikService{}).
+		Owns(&certmanagerv1.Certificate{}).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.requestsForService)).
+		Watches(&traefikv1alpha1.Middleware{}, handler.EnqueueRequestsFromMapFunc(r.requestsForMiddleware)).
+		Named("ingressrequest")
+
+	if r.VaultWatcher != nil {
+		builder = builder.WatchesRawSource(r.VaultWatcher.IngressRequestSource())
+	}
+
+	return builder.Complete(r)
 }
@@ -17,13 +17,21 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
 	traefikv1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -42,6 +50,7 @@ func TestBuildIngressRoute(t *testing.T) {
 		name            string
 		ir              *networkingv1.IngressRequest
 		fqdn            string
+		entry           *utils.DomainEntry
 		wantEntrypoints []string
 		wantServiceName string
 		wantServicePort string
@@ -85,11 +94,31 @@ func TestBuildIngressRoute(t *testing.T) {
 			wantServiceName: testServiceName,
 			wantServicePort: "8080",
 		},
+		{
+			name: "domain entry entrypoints",
+			ir: &networkingv1.IngressRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ingress",
+					Namespace: testNamespace,
+				},
+				Spec: networkingv1.IngressRequestSpec{
+					Subdomain:   testSubdomain,
+					ServiceName: testServiceName,
+					ServicePort: testServicePort,
+					DomainKey:   testDomainKey,
+				},
+			},
+			fqdn:            testFQDN,
+			entry:           &utils.DomainEntry{Domain: "example.com", Entrypoints: []string{"websecure", "internal"}},
+			wantEntrypoints: []string{"websecure", "internal"},
+			wantServiceName: testServiceName,
+			wantServicePort: testServicePort,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			route := reconciler.buildIngressRoute(tt.ir, tt.fqdn)
+			route := reconciler.buildIngressRoute(tt.ir, nil, tt.fqdn, tt.entry)
 
 			// Validate Traefik API structure
 			if route == nil {
@@ -123,7 +152,7 @@ func TestBuildIngressRoute(t *testing.T) {
 				t.Errorf("Service.Name = %v, want %v", service.Name, tt.wantServiceName)
 			}
 
-			portStr := service.Port.StrVal
+			portStr := service.Port.String()
 			if portStr != tt.wantServicePort {
 				t.Errorf("Service.Port = %v, want %v", portStr, tt.wantServicePort)
 			}
@@ -131,18 +160,134 @@ func TestBuildIngressRoute(t *testing.T) {
 	}
 }
 
-// TestBuildServices validates service configuration
-func TestBuildServices(t *testing.T) {
+// TestBuildIngressRouteRoutes validates each request route becomes a Traefik route with its own
+// match, priority, service and middlewares
+func TestBuildIngressRouteRoutes(t *testing.T) {
 	reconciler := &IngressRequestReconciler{}
 
 	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace},
 		Spec: networkingv1.IngressRequestSpec{
-			ServiceName: testSvcName,
+			Subdomain:   testSubdomain,
+			DomainKey:   testDomainKey,
+			ServiceName: "frontend",
 			ServicePort: testServicePort,
+			Middlewares: []networkingv1.MiddlewareRef{{Name: "auth", Namespace: testNamespace}},
+			Routes: []networkingv1.IngressRequestRoute{
+				{
+					PathPrefix:  "/api",
+					Priority:    10,
+					ServiceName: "api",
+					ServicePort: "8080",
+					Middlewares: []networkingv1.MiddlewareRef{{Name: "strip-api", Namespace: testNamespace}},
+				},
+				{PathPrefix: "/"},
+			},
+		},
+	}
+
+	route := reconciler.buildIngressRoute(ir, nil, testFQDN, nil)
+	if len(route.Spec.Routes) != 2 {
+		t.Fatalf("Routes count = %v, want 2", len(route.Spec.Routes))
+	}
+
+	api, root := route.Spec.Routes[0], route.Spec.Routes[1]
+	if api.Match != "Host(`"+testFQDN+"`) && PathPrefix(`/api`)" || api.Priority != 10 {
+		t.Errorf("api route match = %v, priority = %v", api.Match, api.Priority)
+	}
+	if api.Services[0].Name != "api" || api.Services[0].Port != intstr.FromInt32(8080) {
+		t.Errorf("api route service = %s:%s, want api:8080", api.Services[0].Name, api.Services[0].Port.String())
+	}
+	if len(api.Middlewares) != 1 || api.Middlewares[0].Name != "strip-api" {
+		t.Errorf("api route middlewares = %v, want [strip-api]", api.Middlewares)
+	}
+
+	if root.Match != "Host(`"+testFQDN+"`) && PathPrefix(`/`)" || root.Priority != 0 {
+		t.Errorf("root route match = %v, priority = %v", root.Match, root.Priority)
+	}
+	if root.Services[0].Name != "frontend" || root.Services[0].Port.StrVal != testServicePort {
+		t.Errorf("root route service = %s:%s, want the spec's service", root.Services[0].Name, root.Services[0].Port.StrVal)
+	}
+	if len(root.Middlewares) != 1 || root.Middlewares[0].Name != "auth" {
+		t.Errorf("root route middlewares = %v, want the spec's [auth]", root.Middlewares)
+	}
+}
+
+// TestRouteMatch validates the Traefik rule built from a route's matchers
+func TestRouteMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		route networkingv1.IngressRequestRoute
+		want  string
+	}{
+		{
+			name: "host only",
+			want: "Host(`app.example.com`)",
+		},
+		{
+			name:  "exact path",
+			route: networkingv1.IngressRequestRoute{Path: "/healthz"},
+			want:  "Host(`app.example.com`) && Path(`/healthz`)",
+		},
+		{
+			name:  "single method",
+			route: networkingv1.IngressRequestRoute{PathPrefix: "/api", Methods: []string{"GET"}},
+			want:  "Host(`app.example.com`) && PathPrefix(`/api`) && Method(`GET`)",
+		},
+		{
+			name: "methods and headers",
+			route: networkingv1.IngressRequestRoute{
+				Methods: []string{"POST", "PUT"},
+				Headers: []networkingv1.HeaderMatch{{Name: "X-Version", Value: "2"}, {Name: "X-Canary", Value: "true"}},
+			},
+			want: "Host(`app.example.com`) && (Method(`POST`) || Method(`PUT`)) && Header(`X-Version`, `2`) && Header(`X-Canary`, `true`)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routeMatch(testFQDN, tt.route); got != tt.want {
+				t.Errorf("routeMatch = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIngressRequestGetFQDN validates domain lookup through the injected sources
+func TestIngressRequestGetFQDN(t *testing.T) {
+	reconciler := &IngressRequestReconciler{Domains: testDomainSources()}
+
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain: testSubdomain,
+			DomainKey: testDomainKey,
 		},
 	}
 
-	services := reconciler.buildServices(ir)
+	fqdn, _, err := reconciler.getFQDN(context.Background(), ir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fqdn != testFQDN {
+		t.Errorf("FQDN = %v, want %v", fqdn, testFQDN)
+	}
+
+	ir.Spec.DomainKey = "missingDomain"
+	if _, _, err := reconciler.getFQDN(context.Background(), ir); err == nil {
+		t.Error("Expected error for unknown domain key")
+	}
+}
+
+// TestBuildServices validates service configuration
+func TestBuildServices(t *testing.T) {
+	reconciler := &IngressRequestReconciler{}
+
+	ir := &networkingv1.IngressRequest{ObjectMeta: metav1.ObjectMeta{Name: "test-ingress"}}
+	services := reconciler.buildServices(ir, networkingv1.IngressRequestRoute{
+		ServiceName: testSvcName,
+		ServicePort: testServicePort,
+	}, nil)
 
 	if len(services) != 1 {
 		t.Errorf("buildServices returned %d services, want 1", len(services))
@@ -161,6 +306,82 @@ func TestBuildServices(t *testing.T) {
 	if port.StrVal != testServicePort {
 		t.Errorf("Port.StrVal = %v, want http", port.StrVal)
 	}
+
+	// Numeric ports are Service port numbers, not names
+	services = reconciler.buildServices(ir, networkingv1.IngressRequestRoute{ServiceName: testSvcName, ServicePort: "80"}, nil)
+	if port := services[0].Port; port.Type != intstr.Int || port.IntVal != 80 {
+		t.Errorf("Port = %+v, want the number 80", port)
+	}
+
+	// Omitted ports are taken from the resolved Service ports
+	ports := servicePorts{{name: testSvcName}: "8080"}
+	services = reconciler.buildServices(ir, networkingv1.IngressRequestRoute{ServiceName: testSvcName}, ports)
+	if port := services[0].Port; port != intstr.FromInt32(8080) {
+		t.Errorf("Port = %+v, want the resolved 8080", port)
+	}
+
+	// Routes without a Service go to the generated TraefikService
+	services = reconciler.buildServices(ir, networkingv1.IngressRequestRoute{}, nil)
+	if services[0].Name != "test-ingress" || services[0].Kind != traefikServiceKind {
+		t.Errorf("Service = %s/%s, want TraefikService test-ingress", services[0].Kind, services[0].Name)
+	}
+}
+
+// TestCheckService validates port resolution and the reasons reported for missing ports
+func TestCheckService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	services := []client.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "single", Namespace: testNamespace},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: testServicePort, Port: 8080}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "multi", Namespace: testNamespace},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: testServicePort, Port: 80}, {Port: 9090}}},
+		},
+	}
+	reconciler := &IngressRequestReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(services...).Build()}
+
+	tests := []struct {
+		name       string
+		service    string
+		port       string
+		wantPort   string
+		wantReason string
+		wantInMsg  string
+	}{
+		{name: "named port", service: "multi", port: testServicePort, wantPort: testServicePort, wantReason: networkingv1.ReasonServiceFound},
+		{name: "numeric port", service: "multi", port: "9090", wantPort: "9090", wantReason: networkingv1.ReasonServiceFound},
+		{name: "omitted port of a single-port Service", service: "single", wantPort: "8080", wantReason: networkingv1.ReasonServiceFound},
+		{
+			name: "omitted port of a multi-port Service", service: "multi",
+			wantReason: networkingv1.ReasonServicePortRequired, wantInMsg: "http/80, 9090",
+		},
+		{
+			name: "missing named port", service: "single", port: "https", wantPort: "https",
+			wantReason: networkingv1.ReasonServicePortNotFound, wantInMsg: "http/8080",
+		},
+		{
+			name: "missing numeric port", service: "single", port: "80", wantPort: "80",
+			wantReason: networkingv1.ReasonServicePortNotFound, wantInMsg: "http/8080",
+		},
+		{name: "missing Service", service: "missing", port: "80", wantPort: "80", wantReason: networkingv1.ReasonServiceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := client.ObjectKey{Namespace: testNamespace, Name: tt.service}
+			port, check, err := reconciler.checkService(context.Background(), key, tt.port)
+			if err != nil {
+				t.Fatalf("checkService returned error: %v", err)
+			}
+			if port != tt.wantPort || check.reason != tt.wantReason || !strings.Contains(check.message, tt.wantInMsg) {
+				t.Errorf("checkService = %q, %+v, want %q, %s containing %q", port, check, tt.wantPort, tt.wantReason, tt.wantInMsg)
+			}
+		})
+	}
 }
 
 // TestBuildMiddlewares validates middleware conversion
@@ -170,6 +391,7 @@ func TestBuildMiddlewares(t *testing.T) {
 	tests := []struct {
 		name  string
 		ir    *networkingv1.IngressRequest
+		entry *utils.DomainEntry
 		count int
 	}{
 		{
@@ -191,11 +413,30 @@ func TestBuildMiddlewares(t *testing.T) {
 			},
 			count: 2,
 		},
+		{
+			name: "domain entry middlewares",
+			ir: &networkingv1.IngressRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace},
+				Spec:       networkingv1.IngressRequestSpec{},
+			},
+			entry: &utils.DomainEntry{Domain: "example.com", Middlewares: []utils.DomainMiddleware{{Name: "auth"}}},
+			count: 1,
+		},
+		{
+			name: "request overrides domain entry middlewares",
+			ir: &networkingv1.IngressRequest{
+				Spec: networkingv1.IngressRequestSpec{
+					Middlewares: []networkingv1.MiddlewareRef{{Name: "rate-limit", Namespace: "middleware-ns"}},
+				},
+			},
+			entry: &utils.DomainEntry{Domain: "example.com", Middlewares: []utils.DomainMiddleware{{Name: "auth"}, {Name: "geo"}}},
+			count: 1,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			middlewares := reconciler.buildMiddlewares(tt.ir)
+			middlewares := reconciler.buildMiddlewares(tt.ir, tt.entry)
 
 			if len(middlewares) != tt.count {
 				t.Errorf("buildMiddlewares returned %d middlewares, want %d", len(middlewares), tt.count)
@@ -334,3 +575,342 @@ func TestTraefikTLSStructure(t *testing.T) {
 		t.Error("TLS.Options not accessible - Traefik API may have changed")
 	}
 }
+
+// TestEffectiveTLSConfig validates the domain cert resolver only fills an unset TLS source
+// and automatic certificates are only used once issued
+func TestEffectiveTLSConfig(t *testing.T) {
+	entry := &utils.DomainEntry{Domain: "example.com", CertResolver: testLetsEncrypt}
+
+	tests := []struct {
+		name         string
+		tlsSpec      *networkingv1.IngressTLSConfig
+		entry        *utils.DomainEntry
+		wantNil      bool
+		wantSecret   string
+		wantResolver string
+	}{
+		{
+			name:    "no TLS and no domain resolver",
+			entry:   &utils.DomainEntry{Domain: "example.com"},
+			wantNil: true,
+		},
+		{
+			name:         "domain resolver enables TLS",
+			entry:        entry,
+			wantResolver: testLetsEncrypt,
+		},
+		{
+			name:         "empty TLS uses domain resolver",
+			tlsSpec:      &networkingv1.IngressTLSConfig{},
+			entry:        entry,
+			wantResolver: testLetsEncrypt,
+		},
+		{
+			name:       "request secret wins",
+			tlsSpec:    &networkingv1.IngressTLSConfig{SecretName: testTLSSecretName},
+			entry:      entry,
+			wantSecret: testTLSSecretName,
+		},
+		{
+			name:         "request resolver wins",
+			tlsSpec:      &networkingv1.IngressTLSConfig{CertResolver: "internal"},
+			entry:        entry,
+			wantResolver: "internal",
+		},
+		{
+			name:    "pending automatic certificate",
+			tlsSpec: &networkingv1.IngressTLSConfig{AutoCertificate: true},
+			entry:   entry,
+			wantNil: true,
+		},
+		{
+			name:       "issued automatic certificate",
+			tlsSpec:    &networkingv1.IngressTLSConfig{AutoCertificate: true, SecretName: "test-ingress-tls"},
+			entry:      entry,
+			wantSecret: "test-ingress-tls",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := effectiveTLSConfig(tt.tlsSpec, tt.entry)
+
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("effectiveTLSConfig = %+v, want nil", got)
+				}
+				return
+			}
+
+			if got == nil {
+				t.Fatal("effectiveTLSConfig returned nil")
+			}
+			if got.SecretName != tt.wantSecret || got.CertResolver != tt.wantResolver {
+				t.Errorf("effectiveTLSConfig = %+v, want secret %q resolver %q", got, tt.wantSecret, tt.wantResolver)
+			}
+		})
+	}
+}
+
+// TestServiceHasPort validates port lookup by number and name
+func TestServiceHasPort(t *testing.T) {
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: testServicePort, Port: 8080}},
+		},
+	}
+
+	tests := []struct {
+		port string
+		want bool
+	}{
+		{port: testServicePort, want: true},
+		{port: "8080", want: true},
+		{port: "https", want: false},
+		{port: "443", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := serviceHasPort(svc, tt.port); got != tt.want {
+			t.Errorf("serviceHasPort(%s) = %v, want %v", tt.port, got, tt.want)
+		}
+	}
+}
+
+// TestIngressRequestReconcileStatus validates the backend and middleware conditions
+func TestIngressRequestReconcileStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = traefikv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain:   testSubdomain,
+			ServiceName: testServiceName,
+			ServicePort: testServicePort,
+			DomainKey:   testDomainKey,
+			Middlewares: []networkingv1.MiddlewareRef{{Name: "auth", Namespace: testNamespace}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(ir).
+		WithStatusSubresource(&networkingv1.IngressRequest{}).
+		Build()
+	reconciler := &IngressRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(ir)
+	reconcileAndGet := func() *networkingv1.IngressRequest {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var got networkingv1.IngressRequest
+		if err := fakeClient.Get(ctx, key, &got); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+	wantReady := func(got *networkingv1.IngressRequest, status metav1.ConditionStatus, reason string) {
+		t.Helper()
+		cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady)
+		if cond == nil || cond.Status != status || cond.Reason != reason {
+			t.Errorf("Ready condition = %+v, want %s/%s", cond, status, reason)
+		}
+	}
+
+	got := reconcileAndGet()
+	if got.Status.FQDN != testFQDN || got.Status.ObservedGeneration != 1 {
+		t.Errorf("status = %+v, want FQDN %s at generation 1", got.Status, testFQDN)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, networkingv1.ConditionVaultResolved) {
+		t.Error("VaultResolved should be True")
+	}
+	wantReady(got, metav1.ConditionFalse, networkingv1.ReasonServiceNotFound)
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: testServiceName, Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "https", Port: 443}}},
+	}
+	if err := fakeClient.Create(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+	wantReady(reconcileAndGet(), metav1.ConditionFalse, networkingv1.ReasonServicePortNotFound)
+
+	svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Name: testServicePort, Port: 80})
+	if err := fakeClient.Update(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+	got = reconcileAndGet()
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, networkingv1.ConditionServiceResolved) {
+		t.Error("ServiceResolved should be True")
+	}
+	wantReady(got, metav1.ConditionFalse, networkingv1.ReasonMiddlewareNotFound)
+
+	middleware := &traefikv1alpha1.Middleware{ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: testNamespace}}
+	if err := fakeClient.Create(ctx, middleware); err != nil {
+		t.Fatal(err)
+	}
+	got = reconcileAndGet()
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, networkingv1.ConditionMiddlewaresResolved) {
+		t.Error("MiddlewaresResolved should be True")
+	}
+	wantReady(got, metav1.ConditionTrue, networkingv1.ReasonRouteReady)
+}
+
+// TestIngressRequestRoutesStatus validates the Service of every route is checked
+func TestIngressRequestRoutesStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = traefikv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain:   testSubdomain,
+			DomainKey:   testDomainKey,
+			ServiceName: "frontend",
+			ServicePort: testServicePort,
+			Routes: []networkingv1.IngressRequestRoute{
+				{PathPrefix: "/api", ServiceName: "api"},
+				{PathPrefix: "/"},
+			},
+		},
+	}
+	frontend := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: testServicePort, Port: 80}}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(ir, frontend).
+		WithStatusSubresource(&networkingv1.IngressRequest{}).
+		Build()
+	reconciler := &IngressRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(ir)
+	readyCondition := func() *metav1.Condition {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var got networkingv1.IngressRequest
+		if err := fakeClient.Get(ctx, key, &got); err != nil {
+			t.Fatal(err)
+		}
+		return meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady)
+	}
+
+	if cond := readyCondition(); cond == nil || cond.Reason != networkingv1.ReasonServiceNotFound ||
+		!strings.Contains(cond.Message, "/api") {
+		t.Errorf("Ready condition = %+v, want ServiceNotFound for api", cond)
+	}
+
+	api := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: testServicePort, Port: 8080}}},
+	}
+	if err := fakeClient.Create(ctx, api); err != nil {
+		t.Fatal(err)
+	}
+	if cond := readyCondition(); cond == nil || cond.Status != metav1.ConditionTrue ||
+		cond.Message != "Routing "+testFQDN+" to api, frontend" {
+		t.Errorf("Ready condition = %+v, want routing to api and frontend", cond)
+	}
+}
+
+// TestIngressRequestDriftCorrection validates manual IngressRoute edits are reverted and reported,
+// while fields set by others and request changes are not treated as drift
+func TestIngressRequestDriftCorrection(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = traefikv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain:   testSubdomain,
+			ServiceName: testServiceName,
+			ServicePort: testServicePort,
+			DomainKey:   testDomainKey,
+			Entrypoints: []string{"websecure"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(ir).
+		WithStatusSubresource(&networkingv1.IngressRequest{}).
+		Build()
+	recorder := events.NewFakeRecorder(10)
+	reconciler := &IngressRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources(), Recorder: recorder}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(ir)
+	reconcileAndGetRoute := func() *traefikv1alpha1.IngressRoute {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var route traefikv1alpha1.IngressRoute
+		if err := fakeClient.Get(ctx, key, &route); err != nil {
+			t.Fatal(err)
+		}
+		return &route
+	}
+	wantEvents := func(want int) {
+		t.Helper()
+		if got := len(recorder.Events); got != want {
+			t.Fatalf("recorded %d events, want %d", got, want)
+		}
+		for range want {
+			if event := <-recorder.Events; !strings.Contains(event, eventReasonDriftCorrected) {
+				t.Errorf("event = %q, want reason %s", event, eventReasonDriftCorrected)
+			}
+		}
+	}
+
+	route := reconcileAndGetRoute()
+	if route.Annotations[specHashAnnotation] == "" {
+		t.Error("expected the applied IngressRoute to record its spec hash")
+	}
+	wantEvents(0)
+
+	// Labels added by someone else are kept, and are not drift
+	route.Labels = map[string]string{"team": "platform"}
+	if err := fakeClient.Update(ctx, route); err != nil {
+		t.Fatal(err)
+	}
+	route = reconcileAndGetRoute()
+	if route.Labels["team"] != "platform" {
+		t.Errorf("labels = %v, want the team label kept", route.Labels)
+	}
+	wantEvents(0)
+
+	// A manual spec edit is reverted and reported
+	route.Spec.EntryPoints = []string{"web"}
+	if err := fakeClient.Update(ctx, route); err != nil {
+		t.Fatal(err)
+	}
+	route = reconcileAndGetRoute()
+	if len(route.Spec.EntryPoints) != 1 || route.Spec.EntryPoints[0] != "websecure" {
+		t.Errorf("EntryPoints = %v, want [websecure]", route.Spec.EntryPoints)
+	}
+	wantEvents(1)
+
+	// Changing the request is not drift
+	if err := fakeClient.Get(ctx, key, ir); err != nil {
+		t.Fatal(err)
+	}
+	ir.Spec.Entrypoints = []string{"web"}
+	if err := fakeClient.Update(ctx, ir); err != nil {
+		t.Fatal(err)
+	}
+	route = reconcileAndGetRoute()
+	if len(route.Spec.EntryPoints) != 1 || route.Spec.EntryPoints[0] != "web" {
+		t.Errorf("EntryPoints = %v, want [web]", route.Spec.EntryPoints)
+	}
+	wantEvents(0)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	traefikv1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+// ingressProtocol returns the protocol of ir, HTTP when unset
+func ingressProtocol(ir *networkingv1.IngressRequest) networkingv1.IngressProtocol {
+	if ir.Spec.Protocol == "" {
+		return networkingv1.IngressProtocolHTTP
+	}
+	return ir.Spec.Protocol
+}
+
+// layer4Backends returns the Services a TCP or UDP request balances between: its backends, or serviceName
+func layer4Backends(ir *networkingv1.IngressRequest) []networkingv1.WeightedBackend {
+	if len(ir.Spec.Backends) > 0 {
+		return ir.Spec.Backends
+	}
+	return []networkingv1.WeightedBackend{{ServiceName: ir.Spec.ServiceName, ServicePort: ir.Spec.ServicePort}}
+}
+
+// hostSNIMatch builds the TCP rule for fqdn. Traefik only sees the SNI of TLS connections,
+// so connections without TLS match every host.
+func hostSNIMatch(fqdn string, tlsSpec *networkingv1.IngressTLSConfig) string {
+	if tlsSpec == nil {
+		return "HostSNI(`*`)"
+	}
+	return fmt.Sprintf("HostSNI(`%s`)", fqdn)
+}
+
+// buildIngressRouteTCP constructs the desired IngressRouteTCP resource, routing to the Service ports resolved in ports
+func (r *IngressRequestReconciler) buildIngressRouteTCP(ir *networkingv1.IngressRequest, ports servicePorts,
+	fqdn string) *traefikv1alpha1.IngressRouteTCP {
+	// The domain entry's cert resolver only applies to HTTP
+	tlsSpec := effectiveTLSConfig(ir.Spec.TLS, &utils.DomainEntry{})
+
+	backends := layer4Backends(ir)
+	services := make([]traefikv1alpha1.ServiceTCP, 0, len(backends))
+	for _, backend := range backends {
+		services = append(services, traefikv1alpha1.ServiceTCP{
+			Name:   backend.ServiceName,
+			Port:   ports.port(backend.ServiceName, backend.ServicePort),
+			Weight: backend.Weight,
+		})
+	}
+
+	route := &traefikv1alpha1.IngressRouteTCP{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ir.Name,
+			Namespace: ir.Namespace,
+		},
+		Spec: traefikv1alpha1.IngressRouteTCPSpec{
+			EntryPoints: ir.Spec.Entrypoints,
+			Routes: []traefikv1alpha1.RouteTCP{{
+				Match:    hostSNIMatch(fqdn, tlsSpec),
+				Services: services,
+			}},
+		},
+	}
+
+	if tlsSpec != nil {
+		if tlsSpec.Passthrough {
+			route.Spec.TLS = &traefikv1alpha1.TLSTCP{Passthrough: true}
+		} else {
+			route.Spec.TLS = &traefikv1alpha1.TLSTCP{SecretName: tlsSpec.SecretName, CertResolver: tlsSpec.CertResolver}
+		}
+	}
+
+	return route
+}
+
+// buildIngressRouteUDP constructs the desired IngressRouteUDP resource, routing to the Service ports resolved in ports
+func (r *IngressRequestReconciler) buildIngressRouteUDP(ir *networkingv1.IngressRequest,
+	ports servicePorts) *traefikv1alpha1.IngressRouteUDP {
+	backends := layer4Backends(ir)
+	services := make([]traefikv1alpha1.ServiceUDP, 0, len(backends))
+	for _, backend := range backends {
+		services = append(services, traefikv1alpha1.ServiceUDP{
+			Name:   backend.ServiceName,
+			Port:   ports.port(backend.ServiceName, backend.ServicePort),
+			Weight: backend.Weight,
+		})
+	}
+
+	return &traefikv1alpha1.IngressRouteUDP{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ir.Name,
+			Namespace: ir.Namespace,
+		},
+		Spec: traefikv1alpha1.IngressRouteUDPSpec{
+			EntryPoints: ir.Spec.Entrypoints,
+			Routes:      []traefikv1alpha1.RouteUDP{{Services: services}},
+		},
+	}
+}
+
+// applyIngressRouteTCP server-side applies the IngressRouteTCP, reporting manual changes to it as drift
+func (r *IngressRequestReconciler) applyIngressRouteTCP(ctx context.Context, ir *networkingv1.IngressRequest,
+	route *traefikv1alpha1.IngressRouteTCP) error {
+	return r.applyGenerated(ctx, ir, route, &traefikv1alpha1.IngressRouteTCP{}, "IngressRouteTCP",
+		func(obj client.Object) interface{} { return obj.(*traefikv1alpha1.IngressRouteTCP).Spec })
+}
+
+// applyIngressRouteUDP server-side applies the IngressRouteUDP, reporting manual changes to it as drift
+func (r *IngressRequestReconciler) applyIngressRouteUDP(ctx context.Context, ir *networkingv1.IngressRequest,
+	route *traefikv1alpha1.IngressRouteUDP) error {
+	return r.applyGenerated(ctx, ir, route, &traefikv1alpha1.IngressRouteUDP{}, "IngressRouteUDP",
+		func(obj client.Object) interface{} { return obj.(*traefikv1alpha1.IngressRouteUDP).Spec })
+}
+
+// deleteStaleRoutes deletes the generated routes of the protocols ir no longer uses
+func (r *IngressRequestReconciler) deleteStaleRoutes(ctx context.Context, ir *networkingv1.IngressRequest) error {
+	routes := map[networkingv1.IngressProtocol]client.Object{
+		networkingv1.IngressProtocolHTTP: &traefikv1alpha1.IngressRoute{},
+		networkingv1.IngressProtocolTCP:  &traefikv1alpha1.IngressRouteTCP{},
+		networkingv1.IngressProtocolUDP:  &traefikv1alpha1.IngressRouteUDP{},
+	}
+	for protocol, route := range routes {
+		if protocol == ingressProtocol(ir) {
+			continue
+		}
+		if err := r.deleteControlled(ctx, ir, route, ir.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteControlled deletes the object name of obj's kind in the namespace of ir when ir controls it
+func (r *IngressRequestReconciler) deleteControlled(ctx context.Context, ir *networkingv1.IngressRequest,
+	obj client.Object, name string) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return fmt.Errorf("failed to get GroupVersionKind: %w", err)
+	}
+	kind := gvk.Kind
+
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ir.Namespace, Name: name}, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+
+	if !metav1.IsControlledBy(obj, ir) {
+		return nil
+	}
+	if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete %s %s: %w", kind, name, err)
+	}
+	log.FromContext(ctx).Info("Deleted "+kind, "name", name, "namespace", ir.Namespace)
+	return nil
+}
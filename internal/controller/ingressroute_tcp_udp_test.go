@@ -0,0 +1,250 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	traefikv1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+// TestBuildIngressRouteTCP validates the SNI match, TLS and services of the generated IngressRouteTCP
+func TestBuildIngressRouteTCP(t *testing.T) {
+	reconciler := &IngressRequestReconciler{}
+	ports := servicePorts{{name: "postgres"}: "5432"}
+
+	tests := []struct {
+		name         string
+		spec         networkingv1.IngressRequestSpec
+		wantMatch    string
+		wantTLS      *traefikv1alpha1.TLSTCP
+		wantServices []traefikv1alpha1.ServiceTCP
+	}{
+		{
+			name:         "plain TCP",
+			spec:         networkingv1.IngressRequestSpec{ServiceName: "postgres"},
+			wantMatch:    "HostSNI(`*`)",
+			wantServices: []traefikv1alpha1.ServiceTCP{{Name: "postgres", Port: intstr.FromInt32(5432)}},
+		},
+		{
+			name: "TLS termination",
+			spec: networkingv1.IngressRequestSpec{
+				ServiceName: "mqtt",
+				ServicePort: "1883",
+				TLS:         &networkingv1.IngressTLSConfig{SecretName: testTLSSecretName},
+			},
+			wantMatch:    "HostSNI(`" + testFQDN + "`)",
+			wantTLS:      &traefikv1alpha1.TLSTCP{SecretName: testTLSSecretName},
+			wantServices: []traefikv1alpha1.ServiceTCP{{Name: "mqtt", Port: intstr.FromInt32(1883)}},
+		},
+		{
+			name: "TLS passthrough",
+			spec: networkingv1.IngressRequestSpec{
+				ServiceName: "postgres",
+				TLS:         &networkingv1.IngressTLSConfig{Passthrough: true, CertResolver: testLetsEncrypt},
+			},
+			wantMatch:    "HostSNI(`" + testFQDN + "`)",
+			wantTLS:      &traefikv1alpha1.TLSTCP{Passthrough: true},
+			wantServices: []traefikv1alpha1.ServiceTCP{{Name: "postgres", Port: intstr.FromInt32(5432)}},
+		},
+		{
+			name: "weighted backends",
+			spec: networkingv1.IngressRequestSpec{
+				Backends: []networkingv1.WeightedBackend{
+					{ServiceName: "game-a", ServicePort: "25565", Weight: intPtr(3)},
+					{ServiceName: "game-b", ServicePort: "minecraft", Weight: intPtr(1)},
+				},
+			},
+			wantMatch: "HostSNI(`*`)",
+			wantServices: []traefikv1alpha1.ServiceTCP{
+				{Name: "game-a", Port: intstr.FromInt32(25565), Weight: intPtr(3)},
+				{Name: "game-b", Port: intstr.FromString("minecraft"), Weight: intPtr(1)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Protocol = networkingv1.IngressProtocolTCP
+			tt.spec.Entrypoints = []string{"tcp"}
+			ir := &networkingv1.IngressRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace},
+				Spec:       tt.spec,
+			}
+
+			route := reconciler.buildIngressRouteTCP(ir, ports, testFQDN)
+			if route.Name != ir.Name || route.Namespace != testNamespace {
+				t.Errorf("route = %s/%s, want %s/%s", route.Namespace, route.Name, testNamespace, ir.Name)
+			}
+			if len(route.Spec.EntryPoints) != 1 || route.Spec.EntryPoints[0] != "tcp" {
+				t.Errorf("entrypoints = %v, want [tcp]", route.Spec.EntryPoints)
+			}
+			if len(route.Spec.Routes) != 1 {
+				t.Fatalf("routes = %d, want 1", len(route.Spec.Routes))
+			}
+			if got := route.Spec.Routes[0].Match; got != tt.wantMatch {
+				t.Errorf("match = %q, want %q", got, tt.wantMatch)
+			}
+			if (route.Spec.TLS == nil) != (tt.wantTLS == nil) || (tt.wantTLS != nil && !reflect.DeepEqual(route.Spec.TLS, tt.wantTLS)) {
+				t.Errorf("TLS = %+v, want %+v", route.Spec.TLS, tt.wantTLS)
+			}
+
+			services := route.Spec.Routes[0].Services
+			if len(services) != len(tt.wantServices) {
+				t.Fatalf("services = %+v, want %+v", services, tt.wantServices)
+			}
+			for i, want := range tt.wantServices {
+				got := services[i]
+				if got.Name != want.Name || got.Port != want.Port ||
+					(got.Weight == nil) != (want.Weight == nil) || (want.Weight != nil && *got.Weight != *want.Weight) {
+					t.Errorf("service %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestBuildIngressRouteUDP validates the entrypoints and services of the generated IngressRouteUDP
+func TestBuildIngressRouteUDP(t *testing.T) {
+	reconciler := &IngressRequestReconciler{}
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace},
+		Spec: networkingv1.IngressRequestSpec{
+			Protocol:    networkingv1.IngressProtocolUDP,
+			Entrypoints: []string{"game-udp"},
+			ServiceName: "game",
+			ServicePort: "query",
+		},
+	}
+
+	route := reconciler.buildIngressRouteUDP(ir, servicePorts{})
+	if len(route.Spec.EntryPoints) != 1 || route.Spec.EntryPoints[0] != "game-udp" {
+		t.Errorf("entrypoints = %v, want [game-udp]", route.Spec.EntryPoints)
+	}
+	if len(route.Spec.Routes) != 1 || len(route.Spec.Routes[0].Services) != 1 {
+		t.Fatalf("routes = %+v, want one route to one service", route.Spec.Routes)
+	}
+	if svc := route.Spec.Routes[0].Services[0]; svc.Name != "game" || svc.Port != intstr.FromString("query") {
+		t.Errorf("service = %s:%s, want game:query", svc.Name, svc.Port.String())
+	}
+}
+
+// TestIngressRequestProtocolSwitch validates the route of the request's protocol is generated
+// and the routes of the previous protocols are removed
+func TestIngressRequestProtocolSwitch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = traefikv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain:   testSubdomain,
+			DomainKey:   testDomainKey,
+			ServiceName: testServiceName,
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: testServiceName, Namespace: testNamespace},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "postgres", Port: 5432, Protocol: corev1.ProtocolTCP},
+		}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(ir, svc).
+		WithStatusSubresource(&networkingv1.IngressRequest{}).
+		Build()
+	reconciler := &IngressRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(ir)
+	reconcileAndGet := func() *networkingv1.IngressRequest {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var got networkingv1.IngressRequest
+		if err := fakeClient.Get(ctx, key, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !meta.IsStatusConditionTrue(got.Status.Conditions, networkingv1.ConditionReady) {
+			t.Errorf("Ready condition = %+v, want True",
+				meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady))
+		}
+		return &got
+	}
+	setProtocol := func(got *networkingv1.IngressRequest, protocol networkingv1.IngressProtocol) {
+		t.Helper()
+		got.Spec.Protocol = protocol
+		got.Spec.Entrypoints = []string{"postgres"}
+		if err := fakeClient.Update(ctx, got); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantOnly := func(want networkingv1.IngressProtocol) {
+		t.Helper()
+		routes := map[networkingv1.IngressProtocol]client.Object{
+			networkingv1.IngressProtocolHTTP: &traefikv1alpha1.IngressRoute{},
+			networkingv1.IngressProtocolTCP:  &traefikv1alpha1.IngressRouteTCP{},
+			networkingv1.IngressProtocolUDP:  &traefikv1alpha1.IngressRouteUDP{},
+		}
+		for protocol, route := range routes {
+			err := fakeClient.Get(ctx, key, route)
+			if protocol == want && err != nil {
+				t.Errorf("expected the %s route: %v", protocol, err)
+			}
+			if protocol != want && !errors.IsNotFound(err) {
+				t.Errorf("expected the %s route to be deleted, got %v", protocol, err)
+			}
+		}
+	}
+
+	got := reconcileAndGet()
+	wantOnly(networkingv1.IngressProtocolHTTP)
+
+	setProtocol(got, networkingv1.IngressProtocolTCP)
+	got = reconcileAndGet()
+	wantOnly(networkingv1.IngressProtocolTCP)
+	var tcp traefikv1alpha1.IngressRouteTCP
+	if err := fakeClient.Get(ctx, key, &tcp); err != nil {
+		t.Fatal(err)
+	}
+	if owner := metav1.GetControllerOf(&tcp); owner == nil || owner.Name != ir.Name {
+		t.Errorf("owner references = %v, want the IngressRequest", tcp.OwnerReferences)
+	}
+	if port := tcp.Spec.Routes[0].Services[0].Port; port != intstr.FromInt32(5432) {
+		t.Errorf("port = %s, want the Service's only port 5432", port.String())
+	}
+
+	setProtocol(got, networkingv1.IngressProtocolUDP)
+	reconcileAndGet()
+	wantOnly(networkingv1.IngressProtocolUDP)
+}
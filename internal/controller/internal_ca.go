@@ -0,0 +1,257 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+const (
+	// internalCACommonName is the subject of the CA generated when none exists
+	internalCACommonName = "homelab-alm internal CA"
+	// internalCADuration is the lifetime of the generated CA
+	internalCADuration = 10 * 365 * 24 * time.Hour
+)
+
+// InternalCA signs certificates for the InternalCA backend with a CA key pair
+// kept in a Secret or in Vault, under tls.crt and tls.key
+type InternalCA struct {
+	// Client reads the CA Secret and creates it when missing
+	Client client.Client
+	// Secret holds the CA key pair; a self-signed CA is generated into it when missing
+	Secret types.NamespacedName
+	// VaultPath is a KV path holding the CA key pair on the default Vault connection.
+	// It takes precedence over Secret; Vault CAs are never generated.
+	VaultPath string
+	// ReadVault reads VaultPath, bypassing the cache
+	ReadVault func(ctx context.Context, ref VaultSecretRef) (*utils.VaultKVSecret, error)
+}
+
+// NewInternalCA creates an InternalCA using the Secret at secretPath (namespace/name) or, when set,
+// the Vault KV vaultPath read through clients. It returns nil when neither is configured.
+func NewInternalCA(c client.Client, secretPath, vaultPath string, clients utils.VaultClientProvider) (*InternalCA, error) {
+	if secretPath == "" && vaultPath == "" {
+		return nil, nil
+	}
+
+	ca := &InternalCA{Client: c, VaultPath: vaultPath, ReadVault: VaultReader(clients)}
+	if secretPath != "" {
+		namespace, name, ok := strings.Cut(secretPath, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("CA Secret must be namespace/name, got %q", secretPath)
+		}
+		ca.Secret = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+	return ca, nil
+}
+
+// caKeyPair is a loaded signing CA
+type caKeyPair struct {
+	certs []*x509.Certificate
+	key   crypto.Signer
+	caPEM []byte
+}
+
+// reference implements directIssuer
+func (ca *InternalCA) reference() string {
+	return string(networkingv1.CertificateBackendInternalCA)
+}
+
+// caPEM implements directIssuer
+func (ca *InternalCA) caPEM(ctx context.Context) ([]byte, error) {
+	pair, err := ca.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pair.caPEM, nil
+}
+
+// issue implements directIssuer, signing a new key pair with the CA
+func (ca *InternalCA) issue(ctx context.Context, cert *certmanagerv1.Certificate) (*issuedCertificate, error) {
+	pair, err := ca.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return signCertificate(pair, cert)
+}
+
+// signCertificate generates a key pair for the spec of cert and signs it with pair
+func signCertificate(pair *caKeyPair, cert *certmanagerv1.Certificate) (*issuedCertificate, error) {
+	key, err := pki.GeneratePrivateKeyForCertificate(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	var encoding certmanagerv1.PrivateKeyEncoding
+	if cert.Spec.PrivateKey != nil {
+		encoding = cert.Spec.PrivateKey.Encoding
+	}
+	keyPEM, err := pki.EncodePrivateKey(key, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	template, err := pki.CertificateTemplateFromCertificate(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build certificate template: %w", err)
+	}
+	template.PublicKey = key.Public()
+
+	bundle, err := pki.SignCSRTemplate(pair.certs, pair.key, template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+
+	return &issuedCertificate{ChainPEM: bundle.ChainPEM, PrivateKeyPEM: keyPEM, CAPEM: bundle.CAPEM}, nil
+}
+
+// load reads the CA key pair from Vault or the Secret, generating the Secret when it is missing
+func (ca *InternalCA) load(ctx context.Context) (*caKeyPair, error) {
+	var certPEM, keyPEM []byte
+	if ca.VaultPath != "" {
+		if ca.ReadVault == nil {
+			return nil, fmt.Errorf("no Vault reader configured")
+		}
+		secret, err := ca.ReadVault(ctx, VaultSecretRef{Path: ca.VaultPath})
+		if err != nil {
+			return nil, err
+		}
+		certValue, _ := secret.Data[corev1.TLSCertKey].(string)
+		keyValue, _ := secret.Data[corev1.TLSPrivateKeyKey].(string)
+		certPEM, keyPEM = []byte(certValue), []byte(keyValue)
+	} else {
+		secret, err := ca.secret(ctx)
+		if err != nil {
+			return nil, err
+		}
+		certPEM, keyPEM = secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	}
+
+	return parseCAKeyPair(certPEM, keyPEM)
+}
+
+// secret returns the CA Secret, generating a self-signed CA into it when it does not exist
+func (ca *InternalCA) secret(ctx context.Context) (*corev1.Secret, error) {
+	var secret corev1.Secret
+	err := ca.Client.Get(ctx, ca.Secret, &secret)
+	if err == nil {
+		return &secret, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get CA Secret %s: %w", ca.Secret, err)
+	}
+
+	certPEM, keyPEM, err := generateCA(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	secret = corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: ca.Secret.Name, Namespace: ca.Secret.Namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}
+	if err := ca.Client.Create(ctx, &secret); err != nil {
+		if errors.IsAlreadyExists(err) {
+			// Another reconcile generated it first
+			return nil, fmt.Errorf("CA Secret %s was created concurrently, retrying", ca.Secret)
+		}
+		return nil, fmt.Errorf("failed to create CA Secret %s: %w", ca.Secret, err)
+	}
+
+	log.FromContext(ctx).Info("Generated internal CA", "secret", ca.Secret)
+	return &secret, nil
+}
+
+// parseCAKeyPair decodes a CA certificate chain and its private key and checks they belong together
+func parseCAKeyPair(certPEM, keyPEM []byte) (*caKeyPair, error) {
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, fmt.Errorf("CA key pair must have %s and %s", corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	certs, err := pki.DecodeX509CertificateChainBytes(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	key, err := pki.DecodePrivateKeyBytes(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA private key: %w", err)
+	}
+
+	if !certs[0].IsCA {
+		return nil, fmt.Errorf("certificate %s is not a CA", certs[0].Subject)
+	}
+	if matches, err := pki.PublicKeyMatchesCertificate(key.Public(), certs[0]); err != nil || !matches {
+		return nil, fmt.Errorf("CA private key does not match certificate %s", certs[0].Subject)
+	}
+
+	bundle, err := pki.ParseSingleCertificateChain(certs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA chain: %w", err)
+	}
+	return &caKeyPair{certs: certs, key: key, caPEM: bundle.CAPEM}, nil
+}
+
+// generateCA creates a self-signed ECDSA P-256 CA valid from now, returning PEM encoded certificate and key
+func generateCA(now time.Time) ([]byte, []byte, error) {
+	key, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: internalCACommonName},
+		NotBefore:             now,
+		NotAfter:              now.Add(internalCADuration),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certPEM, _, err := pki.SignCertificate(template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign CA certificate: %w", err)
+	}
+	keyPEM, err := pki.EncodeECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode CA key: %w", err)
+	}
+
+	return certPEM, keyPEM, nil
+}
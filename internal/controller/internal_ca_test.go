@@ -0,0 +1,298 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+// TestCertificateRequestInternalCA validates the InternalCA backend issues the TLS Secret itself,
+// keeps it while it is current and reissues it when the spec or the CA changes
+func TestCertificateRequestInternalCA(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName:     testSecretName,
+			DomainKey:      testDomainKey,
+			Subdomain:      testSubdomain,
+			SecretTemplate: &networkingv1.SecretTemplate{Labels: map[string]string{"team": "platform"}},
+		},
+	}
+	// Left over from the CertManager backend
+	leftover := &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            certificateName(cr),
+			Namespace:       testNamespace,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "networking.alm.homelab/v1", Kind: "CertificateRequest", Name: cr.Name, Controller: boolPtr(true)}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cr, leftover).
+		WithStatusSubresource(&networkingv1.CertificateRequest{}).
+		Build()
+	caKey := types.NamespacedName{Namespace: "homelab-alm", Name: "internal-ca"}
+	reconciler := &CertificateRequestReconciler{
+		Client:         fakeClient,
+		Scheme:         scheme,
+		Domains:        testDomainSources(),
+		DefaultBackend: networkingv1.CertificateBackendInternalCA,
+		InternalCA:     &InternalCA{Client: fakeClient, Secret: caKey},
+	}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(cr)
+	secretKey := client.ObjectKey{Namespace: testNamespace, Name: testSecretName}
+	reconcileAndGetSecret := func() *corev1.Secret {
+		t.Helper()
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		if result.RequeueAfter <= 0 {
+			t.Errorf("expected a requeue for renewal, got %v", result)
+		}
+		var secret corev1.Secret
+		if err := fakeClient.Get(ctx, secretKey, &secret); err != nil {
+			t.Fatalf("expected TLS Secret: %v", err)
+		}
+		return &secret
+	}
+
+	secret := reconcileAndGetSecret()
+	if secret.Type != corev1.SecretTypeTLS || secret.Labels["team"] != "platform" {
+		t.Errorf("Secret type = %v, labels = %v", secret.Type, secret.Labels)
+	}
+	leaf, err := pki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(secret.Data[cmmeta.TLSCAKey])
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: testFQDN, Roots: roots}); err != nil {
+		t.Errorf("issued certificate does not verify against ca.crt: %v", err)
+	}
+	if _, err := pki.DecodePrivateKeyBytes(secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		t.Errorf("invalid private key: %v", err)
+	}
+
+	var got networkingv1.CertificateRequest
+	if err := fakeClient.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Status.Ready || got.Status.Backend != networkingv1.CertificateBackendInternalCA {
+		t.Errorf("status ready = %v, backend = %v", got.Status.Ready, got.Status.Backend)
+	}
+	if got.Status.NotAfter == nil || !got.Status.NotAfter.Time.Equal(leaf.NotAfter) || got.Status.RenewalTime == nil {
+		t.Errorf("status notAfter = %v, renewalTime = %v, want %v", got.Status.NotAfter, got.Status.RenewalTime, leaf.NotAfter)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionCertificateIssued); cond == nil ||
+		cond.Status != metav1.ConditionTrue {
+		t.Errorf("CertificateIssued condition = %+v, want True", cond)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(leftover), &certmanagerv1.Certificate{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the leftover Certificate to be deleted, got %v", err)
+	}
+	if err := fakeClient.Get(ctx, caKey, &corev1.Secret{}); err != nil {
+		t.Errorf("expected the CA Secret to be generated: %v", err)
+	}
+
+	// A current certificate is kept
+	issued := secret.Data[corev1.TLSCertKey]
+	if secret := reconcileAndGetSecret(); !bytes.Equal(secret.Data[corev1.TLSCertKey], issued) {
+		t.Error("certificate was reissued without changes")
+	}
+
+	// A spec change reissues
+	got.Spec.Duration = &metav1.Duration{Duration: 24 * time.Hour}
+	if err := fakeClient.Update(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	secret = reconcileAndGetSecret()
+	if bytes.Equal(secret.Data[corev1.TLSCertKey], issued) {
+		t.Error("certificate was not reissued after the duration changed")
+	}
+	if leaf, _ := pki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey]); leaf.NotAfter.Sub(leaf.NotBefore) != 24*time.Hour {
+		t.Errorf("lifetime = %v, want 24h", leaf.NotAfter.Sub(leaf.NotBefore))
+	}
+
+	// A new CA reissues
+	issued = secret.Data[corev1.TLSCertKey]
+	if err := fakeClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: caKey.Name, Namespace: caKey.Namespace}}); err != nil {
+		t.Fatal(err)
+	}
+	if secret := reconcileAndGetSecret(); bytes.Equal(secret.Data[corev1.TLSCertKey], issued) {
+		t.Error("certificate was not reissued after the CA changed")
+	}
+}
+
+// TestCertificateRequestInternalCAFailures validates issuance failures are reported on the request
+func TestCertificateRequestInternalCAFailures(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	tests := []struct {
+		name       string
+		objects    []client.Object
+		internalCA bool
+		wantErr    bool
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:       "foreign Secret",
+			objects:    []client.Object{&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: testSecretName, Namespace: testNamespace}}},
+			internalCA: true,
+			wantErr:    true,
+		},
+		{
+			name: "invalid CA",
+			objects: []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: testNamespace},
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte("not a certificate"), corev1.TLSPrivateKeyKey: []byte("key")},
+			}},
+			internalCA: true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+				Spec: networkingv1.CertificateRequestSpec{
+					SecretName: testSecretName,
+					DomainKey:  testDomainKey,
+					Backend:    networkingv1.CertificateBackendInternalCA,
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(append(tt.objects, cr)...).
+				WithStatusSubresource(&networkingv1.CertificateRequest{}).
+				Build()
+			reconciler := &CertificateRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+			if tt.internalCA {
+				reconciler.InternalCA = &InternalCA{
+					Client: fakeClient,
+					Secret: types.NamespacedName{Namespace: testNamespace, Name: "internal-ca"},
+				}
+			}
+
+			ctx := context.Background()
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconcile error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got networkingv1.CertificateRequest
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(cr), &got); err != nil {
+				t.Fatal(err)
+			}
+			if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady); cond == nil ||
+				cond.Status != metav1.ConditionFalse || cond.Reason != networkingv1.ReasonIssuanceFailed {
+				t.Errorf("Ready condition = %+v, want False/%s", cond, networkingv1.ReasonIssuanceFailed)
+			}
+		})
+	}
+}
+
+// TestCurrentCertificate validates when an issued Secret must be reissued
+func TestCurrentCertificate(t *testing.T) {
+	caPEM, caKeyPEM, err := generateCA(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := parseCAKeyPair(caPEM, caKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &certmanagerv1.Certificate{Spec: certmanagerv1.CertificateSpec{CommonName: testFQDN, DNSNames: []string{testFQDN}}}
+	issued, err := signCertificate(ca, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuedSecret := func(hash string, ca []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{specHashAnnotation: hash}},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       issued.ChainPEM,
+				corev1.TLSPrivateKeyKey: issued.PrivateKeyPEM,
+				cmmeta.TLSCAKey:         ca,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		secret     *corev1.Secret
+		wantReason string
+	}{
+		{name: "current", secret: issuedSecret("hash", ca.caPEM)},
+		{name: "empty", secret: &corev1.Secret{}, wantReason: "no certificate issued"},
+		{name: "spec changed", secret: issuedSecret("other", ca.caPEM), wantReason: "spec changed"},
+		{name: "CA changed", secret: issuedSecret("hash", []byte("other")), wantReason: "CA changed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf, reason := currentCertificate(tt.secret, "hash", ca.caPEM)
+			if reason != tt.wantReason || (leaf == nil) != (tt.wantReason != "") {
+				t.Errorf("currentCertificate = %v, %q, want reason %q", leaf != nil, reason, tt.wantReason)
+			}
+		})
+	}
+
+	leaf, _ := pki.DecodeX509CertificateBytes(issued.ChainPEM)
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+	if got := renewalTime(leaf, cert.Spec); !got.Equal(leaf.NotBefore.Add(lifetime * 2 / 3)) {
+		t.Errorf("renewalTime = %v, want two thirds into the lifetime", got)
+	}
+	cert.Spec.RenewBefore = &metav1.Duration{Duration: 24 * time.Hour}
+	if got := renewalTime(leaf, cert.Spec); !got.Equal(leaf.NotAfter.Add(-24 * time.Hour)) {
+		t.Errorf("renewalTime = %v, want 24h before expiry", got)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
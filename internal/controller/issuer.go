@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+const (
+	// issuerIndex indexes CertificateRequests by the issuer recorded in their status
+	issuerIndex = "status.issuer"
+
+	// issuerRequeueInterval is how often a request waiting for its issuer is retried,
+	// in addition to the issuer watches
+	issuerRequeueInterval = 30 * time.Second
+)
+
+// checkIssuer verifies the Issuer or ClusterIssuer referenced by ref exists and is Ready.
+// It returns the condition reason and message; errors are only returned for failed lookups.
+func (r *CertificateRequestReconciler) checkIssuer(ctx context.Context, namespace string,
+	ref cmmeta.IssuerReference) (string, string, error) {
+	var issuer certmanagerv1.GenericIssuer
+	key := client.ObjectKey{Name: ref.Name}
+	if ref.Kind == certmanagerv1.IssuerKind {
+		key.Namespace = namespace
+		issuer = &certmanagerv1.Issuer{}
+	} else {
+		issuer = &certmanagerv1.ClusterIssuer{}
+	}
+
+	if err := r.Get(ctx, key, issuer); err != nil {
+		if errors.IsNotFound(err) {
+			return networkingv1.ReasonIssuerNotReady, fmt.Sprintf("%s %s not found", ref.Kind, key), nil
+		}
+		return "", "", fmt.Errorf("failed to get %s %s: %w", ref.Kind, key, err)
+	}
+
+	for _, cond := range issuer.GetStatus().Conditions {
+		if cond.Type != certmanagerv1.IssuerConditionReady {
+			continue
+		}
+		if cond.Status == cmmeta.ConditionTrue {
+			return networkingv1.ReasonIssuerReady, fmt.Sprintf("%s %s is ready", ref.Kind, key), nil
+		}
+		return networkingv1.ReasonIssuerNotReady, fmt.Sprintf("%s %s is not ready: %s", ref.Kind, key, cond.Message), nil
+	}
+
+	return networkingv1.ReasonIssuerNotReady, fmt.Sprintf("%s %s has not reported readiness", ref.Kind, key), nil
+}
+
+// issuerStatus formats ref for the request status
+func issuerStatus(ref cmmeta.IssuerReference) string {
+	return ref.Kind + "/" + ref.Name
+}
+
+// issuerIndexValue returns the issuerIndex value of a request whose status records issuer
+func issuerIndexValue(namespace, issuer string) string {
+	kind, name, ok := strings.Cut(issuer, "/")
+	if !ok {
+		return ""
+	}
+	if kind == certmanagerv1.IssuerKind {
+		return kind + "/" + namespace + "/" + name
+	}
+	return kind + "/" + name
+}
+
+// requestsForIssuer maps an Issuer to the CertificateRequests using it
+func (r *CertificateRequestReconciler) requestsForIssuer(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.requestsForIssuerIndex(ctx, certmanagerv1.IssuerKind+"/"+obj.GetNamespace()+"/"+obj.GetName())
+}
+
+// requestsForClusterIssuer maps a ClusterIssuer to the CertificateRequests using it
+func (r *CertificateRequestReconciler) requestsForClusterIssuer(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.requestsForIssuerIndex(ctx, certmanagerv1.ClusterIssuerKind+"/"+obj.GetName())
+}
+
+// requestsForIssuerIndex lists the CertificateRequests indexed under value
+func (r *CertificateRequestReconciler) requestsForIssuerIndex(ctx context.Context, value string) []reconcile.Request {
+	var list networkingv1.CertificateRequestList
+	if err := r.List(ctx, &list, client.MatchingFields{issuerIndex: value}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list CertificateRequests", "index", issuerIndex, "value", value)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+	}
+	return requests
+}
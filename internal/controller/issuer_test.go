@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+// TestCertificateRequestIssuerReadiness validates the Certificate is only created once its issuer is Ready
+func TestCertificateRequestIssuerReadiness(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName: testSecretName,
+			DomainKey:  testDomainKey,
+			IssuerName: testIssuerName,
+			IssuerKind: certmanagerv1.IssuerKind,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cr).
+		WithStatusSubresource(&networkingv1.CertificateRequest{}, &certmanagerv1.Issuer{}).
+		Build()
+	reconciler := &CertificateRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(cr)
+	certKey := client.ObjectKey{Namespace: testNamespace, Name: certificateName(cr)}
+	reconcileAndGet := func(wantRequeue bool) *networkingv1.CertificateRequest {
+		t.Helper()
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		if (result.RequeueAfter > 0) != wantRequeue {
+			t.Errorf("RequeueAfter = %v, want requeue %v", result.RequeueAfter, wantRequeue)
+		}
+		var got networkingv1.CertificateRequest
+		if err := fakeClient.Get(ctx, key, &got); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+	wantIssuer := func(got *networkingv1.CertificateRequest, status metav1.ConditionStatus, reason string) {
+		t.Helper()
+		cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionIssuerReady)
+		if cond == nil || cond.Status != status || cond.Reason != reason {
+			t.Errorf("IssuerReady condition = %+v, want %s/%s", cond, status, reason)
+		}
+	}
+
+	// Missing issuer
+	got := reconcileAndGet(true)
+	wantIssuer(got, metav1.ConditionFalse, networkingv1.ReasonIssuerNotReady)
+	if got.Status.Issuer != "Issuer/"+testIssuerName {
+		t.Errorf("status.issuer = %v, want Issuer/%s", got.Status.Issuer, testIssuerName)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady); cond == nil ||
+		cond.Status != metav1.ConditionFalse || cond.Reason != networkingv1.ReasonIssuerNotReady {
+		t.Errorf("Ready condition = %+v, want False/%s", cond, networkingv1.ReasonIssuerNotReady)
+	}
+	if err := fakeClient.Get(ctx, certKey, &certmanagerv1.Certificate{}); !errors.IsNotFound(err) {
+		t.Errorf("expected no Certificate while the issuer is missing, got %v", err)
+	}
+
+	// Issuer exists but is not ready
+	issuer := &certmanagerv1.Issuer{ObjectMeta: metav1.ObjectMeta{Name: testIssuerName, Namespace: testNamespace}}
+	if err := fakeClient.Create(ctx, issuer); err != nil {
+		t.Fatal(err)
+	}
+	issuer.Status.Conditions = []certmanagerv1.IssuerCondition{
+		{Type: certmanagerv1.IssuerConditionReady, Status: cmmeta.ConditionFalse, Message: "ACME account not registered"},
+	}
+	if err := fakeClient.Status().Update(ctx, issuer); err != nil {
+		t.Fatal(err)
+	}
+	wantIssuer(reconcileAndGet(true), metav1.ConditionFalse, networkingv1.ReasonIssuerNotReady)
+
+	// Issuer becomes ready
+	issuer.Status.Conditions[0].Status = cmmeta.ConditionTrue
+	if err := fakeClient.Status().Update(ctx, issuer); err != nil {
+		t.Fatal(err)
+	}
+	wantIssuer(reconcileAndGet(false), metav1.ConditionTrue, networkingv1.ReasonIssuerReady)
+	if err := fakeClient.Get(ctx, certKey, &certmanagerv1.Certificate{}); err != nil {
+		t.Errorf("expected the Certificate once the issuer is ready, got %v", err)
+	}
+}
+
+// TestIssuerIndexValue validates issuer index values match the keys the issuer watches look up
+func TestIssuerIndexValue(t *testing.T) {
+	tests := []struct {
+		issuer string
+		want   string
+	}{
+		{issuer: "Issuer/" + testIssuerName, want: "Issuer/" + testNamespace + "/" + testIssuerName},
+		{issuer: "ClusterIssuer/ca-issuer", want: "ClusterIssuer/ca-issuer"},
+		{issuer: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := issuerIndexValue(testNamespace, tt.issuer); got != tt.want {
+			t.Errorf("issuerIndexValue(%q) = %v, want %v", tt.issuer, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+const (
+	// DefaultClusterResourceNamespace is where cert-manager reads the Secrets of ClusterIssuers by default
+	DefaultClusterResourceNamespace = "cert-manager"
+
+	letsEncryptStagingServer    = "https://acme-staging-v02.api.letsencrypt.org/directory"
+	letsEncryptProductionServer = "https://acme-v02.api.letsencrypt.org/directory"
+
+	acmeEnvironmentStaging    = "Staging"
+	dns01ProviderCloudflare   = "Cloudflare"
+	dns01ProviderDigitalOcean = "DigitalOcean"
+
+	// dns01CredentialsKey is the key the solver token is stored under in the credentials Secret
+	dns01CredentialsKey = "api-token"
+
+	// credentialsRefreshInterval is how often solver credentials are re-read from Vault
+	credentialsRefreshInterval = 10 * time.Minute
+)
+
+// IssuerProfileReconciler reconciles an IssuerProfile object into a cert-manager ClusterIssuer
+type IssuerProfileReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// ClusterResourceNamespace is cert-manager's cluster resource namespace, where solver credentials are written
+	ClusterResourceNamespace string
+	// ReadVault fetches the KV secret holding solver credentials, bypassing the cache
+	ReadVault func(ctx context.Context, ref VaultSecretRef) (*utils.VaultKVSecret, error)
+}
+
+// +kubebuilder:rbac:groups=networking.alm.homelab,resources=issuerprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.alm.homelab,resources=issuerprofiles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=networking.alm.homelab,resources=issuerprofiles/finalizers,verbs=update
+// +kubebuilder:rbac:groups=cert-manager.io,resources=clusterissuers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;update;patch
+// +kubebuilder:rbac:groups=networking.alm.homelab,resources=clustervaultconnections,verbs=get
+
+func (r *IssuerProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var profile networkingv1.IssuerProfile
+	if err := r.Get(ctx, req.NamespacedName, &profile); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil // profile deleted, the ClusterIssuer is garbage collected
+		}
+		logger.Error(err, "failed to get IssuerProfile")
+		return ctrl.Result{}, err
+	}
+
+	original := profile.Status.DeepCopy()
+	profile.Status.IssuerName = profile.Name
+
+	// Refuse to take over a ClusterIssuer created by someone else
+	var existing certmanagerv1.ClusterIssuer
+	if err := r.Get(ctx, client.ObjectKey{Name: profile.Name}, &existing); err == nil {
+		if !metav1.IsControlledBy(&existing, &profile) {
+			message := fmt.Sprintf("ClusterIssuer %s exists and is not managed by this profile", profile.Name)
+			setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionReady,
+				metav1.ConditionFalse, networkingv1.ReasonIssuerConflict, message)
+			return ctrl.Result{}, r.updateStatus(ctx, &profile, original)
+		}
+	} else if !errors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to get ClusterIssuer %s: %w", profile.Name, err)
+	}
+
+	// Refuse to overwrite a credentials Secret created by someone else
+	if profile.Spec.ACME != nil {
+		conflict, err := r.credentialsConflict(ctx, &profile)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if conflict != "" {
+			setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionCredentialsSynced,
+				metav1.ConditionFalse, networkingv1.ReasonIssuerConflict, conflict)
+			setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionReady,
+				metav1.ConditionFalse, networkingv1.ReasonIssuerConflict, conflict)
+			return ctrl.Result{}, r.updateStatus(ctx, &profile, original)
+		}
+	}
+
+	result := ctrl.Result{}
+	if acme := profile.Spec.ACME; acme != nil {
+		if err := r.syncCredentials(ctx, &profile, acme.DNS01.Credentials); err != nil {
+			logger.Error(err, "failed to sync solver credentials")
+			setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionCredentialsSynced,
+				metav1.ConditionFalse, networkingv1.ReasonCredentialsSyncFailed, err.Error())
+			setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionReady,
+				metav1.ConditionFalse, networkingv1.ReasonCredentialsSyncFailed, err.Error())
+			return ctrl.Result{}, r.updateStatusAfterError(ctx, &profile, original, err)
+		}
+		setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionCredentialsSynced,
+			metav1.ConditionTrue, networkingv1.ReasonCredentialsSynced,
+			fmt.Sprintf("Credentials copied to Secret %s/%s", r.clusterResourceNamespace(), credentialsSecretName(&profile)))
+		// Vault is not watched, so rotated credentials are picked up periodically
+		result.RequeueAfter = credentialsRefreshInterval
+	} else {
+		meta.RemoveStatusCondition(&profile.Status.Conditions, networkingv1.ConditionCredentialsSynced)
+	}
+
+	issuer := buildClusterIssuer(&profile)
+	if err := ctrl.SetControllerReference(&profile, issuer, r.Scheme); err != nil {
+		logger.Error(err, "failed to set controller reference")
+		return ctrl.Result{}, err
+	}
+	if err := applyObject(ctx, r.Client, r.Scheme, issuer); err != nil {
+		err = fmt.Errorf("failed to apply ClusterIssuer %s: %w", issuer.Name, err)
+		setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionReady,
+			metav1.ConditionFalse, networkingv1.ReasonIssuerSyncFailed, err.Error())
+		return ctrl.Result{}, r.updateStatusAfterError(ctx, &profile, original, err)
+	}
+
+	reason, message := clusterIssuerReadiness(issuer)
+	setCondition(&profile.Status.Conditions, profile.Generation, networkingv1.ConditionReady,
+		conditionStatus(reason == networkingv1.ReasonIssuerReady), reason, message)
+
+	return result, r.updateStatus(ctx, &profile, original)
+}
+
+// credentialsConflict returns a message naming the credentials Secret of profile when it exists
+// without being controlled by profile, or an empty string when there is none
+func (r *IssuerProfileReconciler) credentialsConflict(ctx context.Context, profile *networkingv1.IssuerProfile) (string, error) {
+	var existing corev1.Secret
+	key := client.ObjectKey{Namespace: r.clusterResourceNamespace(), Name: credentialsSecretName(profile)}
+	if err := r.Get(ctx, key, &existing); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+	if !metav1.IsControlledBy(&existing, profile) {
+		return fmt.Sprintf("Secret %s exists and is not managed by this profile", key), nil
+	}
+	return "", nil
+}
+
+// syncCredentials copies the solver token from Vault into the Secret the ClusterIssuer references
+func (r *IssuerProfileReconciler) syncCredentials(ctx context.Context, profile *networkingv1.IssuerProfile,
+	credentials networkingv1.VaultCredentials) error {
+	if r.ReadVault == nil {
+		return fmt.Errorf("no Vault reader configured")
+	}
+
+	ref := VaultSecretRef{Connection: vaultConnectionKey("", credentials.VaultConnection), Path: credentials.Path}
+	secret, err := r.ReadVault(ctx, ref)
+	if err != nil {
+		return err
+	}
+	token, ok := secret.Data[credentials.Key].(string)
+	if !ok || token == "" {
+		return fmt.Errorf("key %s not found in Vault secret %s", credentials.Key, credentials.Path)
+	}
+
+	target := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialsSecretName(profile),
+			Namespace: r.clusterResourceNamespace(),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{dns01CredentialsKey: []byte(token)},
+	}
+	if err := ctrl.SetControllerReference(profile, target, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference: %w", err)
+	}
+	if err := applyObject(ctx, r.Client, r.Scheme, target); err != nil {
+		return fmt.Errorf("failed to apply Secret %s/%s: %w", target.Namespace, target.Name, err)
+	}
+
+	return nil
+}
+
+// clusterResourceNamespace returns the namespace solver credentials are written to
+func (r *IssuerProfileReconciler) clusterResourceNamespace() string {
+	return firstNonEmpty(r.ClusterResourceNamespace, DefaultClusterResourceNamespace)
+}
+
+// credentialsSecretName names the Secret holding a profile's solver credentials
+func credentialsSecretName(profile *networkingv1.IssuerProfile) string {
+	return profile.Name + "-dns01-credentials"
+}
+
+// buildClusterIssuer constructs the ClusterIssuer described by profile, named after it
+func buildClusterIssuer(profile *networkingv1.IssuerProfile) *certmanagerv1.ClusterIssuer {
+	issuer := &certmanagerv1.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: profile.Name},
+	}
+
+	if ca := profile.Spec.CA; ca != nil {
+		issuer.Spec.CA = &certmanagerv1.CAIssuer{SecretName: ca.SecretName}
+		return issuer
+	}
+
+	acme := profile.Spec.ACME
+	if acme == nil {
+		return issuer
+	}
+
+	server := letsEncryptProductionServer
+	if acme.Environment == acmeEnvironmentStaging {
+		server = letsEncryptStagingServer
+	}
+	tokenRef := cmmeta.SecretKeySelector{
+		LocalObjectReference: cmmeta.LocalObjectReference{Name: credentialsSecretName(profile)},
+		Key:                  dns01CredentialsKey,
+	}
+
+	dns01 := &cmacme.ACMEChallengeSolverDNS01{}
+	switch acme.DNS01.Provider {
+	case dns01ProviderCloudflare:
+		dns01.Cloudflare = &cmacme.ACMEIssuerDNS01ProviderCloudflare{APIToken: &tokenRef}
+	case dns01ProviderDigitalOcean:
+		dns01.DigitalOcean = &cmacme.ACMEIssuerDNS01ProviderDigitalOcean{Token: tokenRef}
+	}
+
+	issuer.Spec.ACME = &cmacme.ACMEIssuer{
+		Email:  acme.Email,
+		Server: firstNonEmpty(acme.Server, server),
+		PrivateKey: cmmeta.SecretKeySelector{
+			LocalObjectReference: cmmeta.LocalObjectReference{Name: profile.Name + "-acme-account"},
+		},
+		Solvers: []cmacme.ACMEChallengeSolver{{DNS01: dns01}},
+	}
+	return issuer
+}
+
+// clusterIssuerReadiness mirrors the Ready condition of issuer as a condition reason and message
+func clusterIssuerReadiness(issuer *certmanagerv1.ClusterIssuer) (string, string) {
+	for _, cond := range issuer.Status.Conditions {
+		if cond.Type != certmanagerv1.IssuerConditionReady {
+			continue
+		}
+		if cond.Status == cmmeta.ConditionTrue {
+			return networkingv1.ReasonIssuerReady, fmt.Sprintf("ClusterIssuer %s is ready", issuer.Name)
+		}
+		return networkingv1.ReasonIssuerNotReady, fmt.Sprintf("ClusterIssuer %s is not ready: %s", issuer.Name, cond.Message)
+	}
+	return networkingv1.ReasonIssuerNotReady, fmt.Sprintf("ClusterIssuer %s has not reported readiness", issuer.Name)
+}
+
+// updateStatus writes the IssuerProfile status when it differs from original
+func (r *IssuerProfileReconciler) updateStatus(ctx context.Context, profile *networkingv1.IssuerProfile,
+	original *networkingv1.IssuerProfileStatus) error {
+	profile.Status.ObservedGeneration = profile.Generation
+	if equality.Semantic.DeepEqual(*original, profile.Status) {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, profile); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	return nil
+}
+
+// updateStatusAfterError records a failed reconcile in the status and returns the original error
+func (r *IssuerProfileReconciler) updateStatusAfterError(ctx context.Context, profile *networkingv1.IssuerProfile,
+	original *networkingv1.IssuerProfileStatus, err error) error {
+	if statusErr := r.updateStatus(ctx, profile, original); statusErr != nil {
+		log.FromContext(ctx).Error(statusErr, "failed to record error in status")
+	}
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IssuerProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&networkingv1.IssuerProfile{}).
+		Owns(&certmanagerv1.ClusterIssuer{}).
+		Named("issuerprofile").
+		Complete(r)
+}
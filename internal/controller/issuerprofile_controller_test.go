@@ -0,0 +1,296 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+func testACMEProfile(provider string) *networkingv1.IssuerProfile {
+	return &networkingv1.IssuerProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: testLetsEncrypt, UID: "profile-uid"},
+		Spec: networkingv1.IssuerProfileSpec{
+			ACME: &networkingv1.ACMEIssuerProfile{
+				Environment: acmeEnvironmentStaging,
+				Email:       "admin@example.com",
+				DNS01: networkingv1.DNS01SolverProfile{
+					Provider:    provider,
+					Credentials: networkingv1.VaultCredentials{Path: "kv/dns/cloudflare", Key: "api-token"},
+				},
+			},
+		},
+	}
+}
+
+// TestBuildClusterIssuer validates the ClusterIssuer generated for each kind of profile
+func TestBuildClusterIssuer(t *testing.T) {
+	production := testACMEProfile(dns01ProviderDigitalOcean)
+	production.Spec.ACME.Environment = "Production"
+	custom := testACMEProfile(dns01ProviderCloudflare)
+	custom.Spec.ACME.Server = "https://acme.example.com/directory"
+
+	tests := []struct {
+		name       string
+		profile    *networkingv1.IssuerProfile
+		wantServer string
+		check      func(t *testing.T, issuer *certmanagerv1.ClusterIssuer)
+	}{
+		{
+			name:       "staging cloudflare",
+			profile:    testACMEProfile(dns01ProviderCloudflare),
+			wantServer: letsEncryptStagingServer,
+			check: func(t *testing.T, issuer *certmanagerv1.ClusterIssuer) {
+				cloudflare := issuer.Spec.ACME.Solvers[0].DNS01.Cloudflare
+				if cloudflare == nil || cloudflare.APIToken == nil ||
+					cloudflare.APIToken.Name != testLetsEncrypt+"-dns01-credentials" || cloudflare.APIToken.Key != dns01CredentialsKey {
+					t.Errorf("Cloudflare solver = %+v, want the credentials Secret token", cloudflare)
+				}
+			},
+		},
+		{
+			name:       "production digitalocean",
+			profile:    production,
+			wantServer: letsEncryptProductionServer,
+			check: func(t *testing.T, issuer *certmanagerv1.ClusterIssuer) {
+				digitalOcean := issuer.Spec.ACME.Solvers[0].DNS01.DigitalOcean
+				if digitalOcean == nil || digitalOcean.Token.Name != testLetsEncrypt+"-dns01-credentials" {
+					t.Errorf("DigitalOcean solver = %+v, want the credentials Secret token", digitalOcean)
+				}
+			},
+		},
+		{
+			name:       "server override",
+			profile:    custom,
+			wantServer: "https://acme.example.com/directory",
+		},
+		{
+			name: "ca",
+			profile: &networkingv1.IssuerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "homelab-ca"},
+				Spec:       networkingv1.IssuerProfileSpec{CA: &networkingv1.CAIssuerProfile{SecretName: "homelab-ca-keypair"}},
+			},
+			check: func(t *testing.T, issuer *certmanagerv1.ClusterIssuer) {
+				if issuer.Spec.ACME != nil || issuer.Spec.CA == nil || issuer.Spec.CA.SecretName != "homelab-ca-keypair" {
+					t.Errorf("issuer spec = %+v, want a CA issuer", issuer.Spec)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := buildClusterIssuer(tt.profile)
+			if issuer.Name != tt.profile.Name {
+				t.Errorf("name = %v, want %v", issuer.Name, tt.profile.Name)
+			}
+			if tt.wantServer != "" {
+				if issuer.Spec.ACME == nil {
+					t.Fatal("expected an ACME issuer")
+				}
+				if issuer.Spec.ACME.Server != tt.wantServer {
+					t.Errorf("server = %v, want %v", issuer.Spec.ACME.Server, tt.wantServer)
+				}
+				if issuer.Spec.ACME.PrivateKey.Name != tt.profile.Name+"-acme-account" {
+					t.Errorf("account key Secret = %v, want %v-acme-account", issuer.Spec.ACME.PrivateKey.Name, tt.profile.Name)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, issuer)
+			}
+		})
+	}
+}
+
+// TestIssuerProfileReconcile validates credentials are synced from Vault and the ClusterIssuer is generated
+func TestIssuerProfileReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+
+	tests := []struct {
+		name            string
+		objects         []client.Object
+		vaultData       map[string]interface{}
+		vaultErr        error
+		wantErr         bool
+		wantReason      string
+		wantCredentials metav1.ConditionStatus
+		wantIssuer      bool
+	}{
+		{
+			name:            "synced",
+			vaultData:       map[string]interface{}{"api-token": "cf-token"},
+			wantReason:      networkingv1.ReasonIssuerNotReady,
+			wantCredentials: metav1.ConditionTrue,
+			wantIssuer:      true,
+		},
+		{
+			name:            "missing key",
+			vaultData:       map[string]interface{}{"other": "value"},
+			wantErr:         true,
+			wantReason:      networkingv1.ReasonCredentialsSyncFailed,
+			wantCredentials: metav1.ConditionFalse,
+		},
+		{
+			name:            "vault unavailable",
+			vaultErr:        fmt.Errorf("connection refused"),
+			wantErr:         true,
+			wantReason:      networkingv1.ReasonCredentialsSyncFailed,
+			wantCredentials: metav1.ConditionFalse,
+		},
+		{
+			name:       "foreign ClusterIssuer",
+			objects:    []client.Object{&certmanagerv1.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: testLetsEncrypt}}},
+			vaultData:  map[string]interface{}{"api-token": "cf-token"},
+			wantReason: networkingv1.ReasonIssuerConflict,
+		},
+		{
+			name: "foreign credentials Secret",
+			objects: []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: testLetsEncrypt + "-dns01-credentials", Namespace: DefaultClusterResourceNamespace},
+				Data:       map[string][]byte{dns01CredentialsKey: []byte("theirs")},
+			}},
+			vaultData:       map[string]interface{}{"api-token": "cf-token"},
+			wantReason:      networkingv1.ReasonIssuerConflict,
+			wantCredentials: metav1.ConditionFalse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := testACMEProfile(dns01ProviderCloudflare)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(append(tt.objects, profile)...).
+				WithStatusSubresource(&networkingv1.IssuerProfile{}).
+				Build()
+			var readRef VaultSecretRef
+			reconciler := &IssuerProfileReconciler{
+				Client: fakeClient,
+				Scheme: scheme,
+				ReadVault: func(_ context.Context, ref VaultSecretRef) (*utils.VaultKVSecret, error) {
+					readRef = ref
+					if tt.vaultErr != nil {
+						return nil, tt.vaultErr
+					}
+					return &utils.VaultKVSecret{Data: tt.vaultData}, nil
+				},
+			}
+
+			ctx := context.Background()
+			result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(profile)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconcile error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got networkingv1.IssuerProfile
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(profile), &got); err != nil {
+				t.Fatal(err)
+			}
+			if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady); cond == nil ||
+				cond.Status != metav1.ConditionFalse || cond.Reason != tt.wantReason {
+				t.Errorf("Ready condition = %+v, want False/%s", cond, tt.wantReason)
+			}
+			cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionCredentialsSynced)
+			if tt.wantCredentials == "" {
+				if cond != nil {
+					t.Errorf("CredentialsSynced condition = %+v, want none", cond)
+				}
+			} else if cond == nil || cond.Status != tt.wantCredentials {
+				t.Errorf("CredentialsSynced condition = %+v, want %s", cond, tt.wantCredentials)
+			}
+
+			// Secrets created by someone else are left alone
+			for _, obj := range tt.objects {
+				foreign, ok := obj.(*corev1.Secret)
+				if !ok {
+					continue
+				}
+				var secret corev1.Secret
+				if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(foreign), &secret); err != nil {
+					t.Fatal(err)
+				}
+				if string(secret.Data[dns01CredentialsKey]) != "theirs" || len(secret.OwnerReferences) > 0 {
+					t.Errorf("the foreign Secret was overwritten: %v %v", secret.OwnerReferences, secret.Data)
+				}
+			}
+
+			var issuer certmanagerv1.ClusterIssuer
+			err = fakeClient.Get(ctx, client.ObjectKey{Name: testLetsEncrypt}, &issuer)
+			if tt.wantIssuer != (err == nil && metav1.IsControlledBy(&issuer, profile)) {
+				t.Errorf("generated ClusterIssuer = %v (err %v), want %v", issuer.OwnerReferences, err, tt.wantIssuer)
+			}
+			if !tt.wantIssuer {
+				return
+			}
+
+			if readRef.Path != "kv/dns/cloudflare" || readRef.Connection != "" {
+				t.Errorf("read Vault secret %+v, want kv/dns/cloudflare on the default connection", readRef)
+			}
+			if result.RequeueAfter != credentialsRefreshInterval {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, credentialsRefreshInterval)
+			}
+			var secret corev1.Secret
+			secretKey := client.ObjectKey{Namespace: DefaultClusterResourceNamespace, Name: credentialsSecretName(profile)}
+			if err := fakeClient.Get(ctx, secretKey, &secret); err != nil {
+				t.Fatalf("expected credentials Secret %s: %v", secretKey, err)
+			}
+			if string(secret.Data[dns01CredentialsKey]) != "cf-token" {
+				t.Errorf("credentials = %q, want cf-token", secret.Data[dns01CredentialsKey])
+			}
+			if got.Status.IssuerName != testLetsEncrypt {
+				t.Errorf("status.issuerName = %v, want %v", got.Status.IssuerName, testLetsEncrypt)
+			}
+		})
+	}
+}
+
+// TestCertificateRequestIssuerProfile validates a request selecting a profile uses its ClusterIssuer
+func TestCertificateRequestIssuerProfile(t *testing.T) {
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName:    testSecretName,
+			DomainKey:     testDomainKey,
+			IssuerProfile: testLetsEncrypt,
+		},
+	}
+	entry := &utils.DomainEntry{Domain: "example.com", IssuerName: testIssuerName, IssuerKind: certmanagerv1.IssuerKind}
+
+	cert, err := (&CertificateRequestReconciler{}).buildCertificate(cr, "example.com", nil, entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Spec.IssuerRef.Name != testLetsEncrypt || cert.Spec.IssuerRef.Kind != certmanagerv1.ClusterIssuerKind {
+		t.Errorf("IssuerRef = %+v, want ClusterIssuer %s", cert.Spec.IssuerRef, testLetsEncrypt)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+const (
+	// replicaOfLabel marks Secret copies with the UID of the CertificateRequest they belong to
+	replicaOfLabel = "networking.alm.homelab/replica-of"
+	// replicaOfAnnotation records the "namespace/name" of the CertificateRequest a copy belongs to
+	replicaOfAnnotation = "networking.alm.homelab/replica-of"
+	// replicateFromAnnotation opts a namespace in to copies from the comma separated
+	// source namespaces it lists, or from every namespace with "*"
+	replicateFromAnnotation = "networking.alm.homelab/replicate-from"
+)
+
+// SecretReplicaSelector selects the Secret copies made for replicateTo, the only Secrets the operator watches
+func SecretReplicaSelector() labels.Selector {
+	requirement, _ := labels.NewRequirement(replicaOfLabel, selection.Exists, nil)
+	return labels.NewSelector().Add(*requirement)
+}
+
+// replicateSecret copies the TLS Secret of cr into the namespaces selected by spec.replicateTo,
+// removes copies from namespaces that are no longer selected and records the result in the status
+func (r *CertificateRequestReconciler) replicateSecret(ctx context.Context, cr *networkingv1.CertificateRequest) error {
+	if cr.Spec.ReplicateTo == nil && len(cr.Status.ReplicatedNamespaces) == 0 {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, networkingv1.ConditionSecretReplicated)
+		return nil
+	}
+
+	targets, refused, err := r.replicaNamespaces(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	var source corev1.Secret
+	sourceKey := client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.SecretName}
+	sourceFound := true
+	if err := r.Get(ctx, sourceKey, &source); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get Secret %s: %w", sourceKey, err)
+		}
+		sourceFound = false
+	}
+
+	// Copies are only pruned, never written, until cert-manager has issued the Secret
+	conflicts := refused
+	if sourceFound {
+		for _, namespace := range targets {
+			conflict, err := r.applyReplica(ctx, cr, &source, namespace)
+			if err != nil {
+				return err
+			}
+			if conflict != "" {
+				conflicts = append(conflicts, conflict)
+			}
+		}
+	}
+
+	replicated, err := r.pruneReplicas(ctx, cr, targets)
+	if err != nil {
+		return err
+	}
+	cr.Status.ReplicatedNamespaces = replicated
+
+	switch {
+	case cr.Spec.ReplicateTo == nil:
+		meta.RemoveStatusCondition(&cr.Status.Conditions, networkingv1.ConditionSecretReplicated)
+	case !sourceFound:
+		setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionSecretReplicated, metav1.ConditionUnknown,
+			networkingv1.ReasonWaitingForSecret, fmt.Sprintf("Waiting for cert-manager to issue Secret %s", sourceKey))
+	case len(conflicts) > 0:
+		setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionSecretReplicated, metav1.ConditionFalse,
+			networkingv1.ReasonReplicationFailed, strings.Join(conflicts, "; "))
+	default:
+		setCondition(&cr.Status.Conditions, cr.Generation, networkingv1.ConditionSecretReplicated, metav1.ConditionTrue,
+			networkingv1.ReasonReplicated, fmt.Sprintf("Secret copied to %d namespaces", len(replicated)))
+	}
+
+	return nil
+}
+
+// replicaNamespaces returns the sorted namespaces selected by spec.replicateTo that accept copies
+// from the request's namespace, excluding the request's own. Selected namespaces that are missing
+// or have not opted in are returned as refusals.
+func (r *CertificateRequestReconciler) replicaNamespaces(ctx context.Context,
+	cr *networkingv1.CertificateRequest) ([]string, []string, error) {
+	replicateTo := cr.Spec.ReplicateTo
+	if replicateTo == nil {
+		return nil, nil, nil
+	}
+
+	selected := map[string]*corev1.Namespace{}
+	for _, namespace := range replicateTo.Namespaces {
+		selected[namespace] = nil
+	}
+
+	if replicateTo.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(replicateTo.NamespaceSelector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid namespaceSelector: %w", err)
+		}
+		var namespaces corev1.NamespaceList
+		if err := r.List(ctx, &namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		for i := range namespaces.Items {
+			selected[namespaces.Items[i].Name] = &namespaces.Items[i]
+		}
+	}
+
+	delete(selected, cr.Namespace)
+	names := make([]string, 0, len(selected))
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var targets, refused []string
+	for _, name := range names {
+		namespace := selected[name]
+		if namespace == nil {
+			namespace = &corev1.Namespace{}
+			if err := r.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, nil, fmt.Errorf("failed to get namespace %s: %w", name, err)
+				}
+				refused = append(refused, fmt.Sprintf("namespace %s not found", name))
+				continue
+			}
+		}
+		if !allowsReplicaFrom(namespace, cr.Namespace) {
+			refused = append(refused, fmt.Sprintf("namespace %s does not accept copies from %s (annotation %s)",
+				name, cr.Namespace, replicateFromAnnotation))
+			continue
+		}
+		targets = append(targets, name)
+	}
+	return targets, refused, nil
+}
+
+// allowsReplicaFrom reports whether namespace opted in to Secret copies from source
+func allowsReplicaFrom(namespace *corev1.Namespace, source string) bool {
+	for _, allowed := range strings.Split(namespace.Annotations[replicateFromAnnotation], ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || allowed == source {
+			return true
+		}
+	}
+	return false
+}
+
+// applyReplica writes a copy of source into namespace. A Secret of the same name that is not
+// a copy for cr is left alone and reported as a conflict; a missing namespace is reported too.
+func (r *CertificateRequestReconciler) applyReplica(ctx context.Context, cr *networkingv1.CertificateRequest,
+	source *corev1.Secret, namespace string) (string, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: source.Name}
+
+	var existing corev1.Secret
+	if err := r.Get(ctx, key, &existing); err == nil {
+		if existing.Labels[replicaOfLabel] != string(cr.UID) {
+			return fmt.Sprintf("Secret %s exists and is not a copy for this request", key), nil
+		}
+	} else if !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+
+	replica := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        source.Name,
+			Namespace:   namespace,
+			Labels:      map[string]string{replicaOfLabel: string(cr.UID)},
+			Annotations: map[string]string{replicaOfAnnotation: cr.Namespace + "/" + cr.Name},
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+	if err := applyObject(ctx, r.Client, r.Scheme, replica); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("namespace %s not found", namespace), nil
+		}
+		return "", fmt.Errorf("failed to apply Secret %s: %w", key, err)
+	}
+
+	return "", nil
+}
+
+// pruneReplicas deletes the copies for cr outside targets and returns the namespaces that still hold one
+func (r *CertificateRequestReconciler) pruneReplicas(ctx context.Context, cr *networkingv1.CertificateRequest,
+	targets []string) ([]string, error) {
+	replicas, err := r.listReplicas(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := map[string]bool{}
+	for _, namespace := range targets {
+		keep[namespace] = true
+	}
+
+	var replicated []string
+	for i := range replicas {
+		replica := &replicas[i]
+		if keep[replica.Namespace] {
+			replicated = append(replicated, replica.Namespace)
+			continue
+		}
+		if err := r.Delete(ctx, replica); client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("failed to delete Secret %s: %w", client.ObjectKeyFromObject(replica), err)
+		}
+		log.FromContext(ctx).Info("Deleted Secret copy", "namespace", replica.Namespace, "name", replica.Name)
+	}
+
+	sort.Strings(replicated)
+	return replicated, nil
+}
+
+// listReplicas returns the Secret copies made for cr in any namespace
+func (r *CertificateRequestReconciler) listReplicas(ctx context.Context, cr *networkingv1.CertificateRequest) ([]corev1.Secret, error) {
+	var secrets corev1.SecretList
+	if err := r.List(ctx, &secrets, client.MatchingLabels{replicaOfLabel: string(cr.UID)}); err != nil {
+		return nil, fmt.Errorf("failed to list Secret copies: %w", err)
+	}
+	return secrets.Items, nil
+}
+
+// requestForReplica maps a Secret copy to the CertificateRequest it belongs to
+func (r *CertificateRequestReconciler) requestForReplica(_ context.Context, obj client.Object) []reconcile.Request {
+	namespace, name, ok := strings.Cut(obj.GetAnnotations()[replicaOfAnnotation], "/")
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+// requestsForNamespace maps a Namespace to the CertificateRequests that may replicate into it
+func (r *CertificateRequestReconciler) requestsForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list networkingv1.CertificateRequestList
+	if err := r.List(ctx, &list); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list CertificateRequests")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range list.Items {
+		cr := &list.Items[i]
+		if cr.Spec.ReplicateTo != nil || len(cr.Status.ReplicatedNamespaces) > 0 {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+		}
+	}
+	return requests
+}
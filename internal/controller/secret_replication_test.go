@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+// TestCertificateRequestReplicateSecret validates the TLS Secret is copied to the selected namespaces,
+// kept updated, pruned from namespaces no longer selected and removed with the request
+func TestCertificateRequestReplicateSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	shared := map[string]string{"tls": "shared"}
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace, UID: "cr-uid"},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName: testSecretName,
+			DomainKey:  testDomainKey,
+			ReplicateTo: &networkingv1.SecretReplication{
+				Namespaces:        []string{"infra"},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: shared},
+			},
+		},
+	}
+	fromDefault := map[string]string{replicateFromAnnotation: "apps, " + testNamespace}
+	edge := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "edge", Labels: shared, Annotations: fromDefault}}
+	locked := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "locked", Labels: shared}}
+	foreign := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: testSecretName, Namespace: "infra"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			cr, edge, locked, foreign, testClusterIssuer(),
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "infra", Annotations: fromDefault}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "traefik", Labels: shared,
+				Annotations: map[string]string{replicateFromAnnotation: "*"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace, Labels: shared}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		).
+		WithStatusSubresource(&networkingv1.CertificateRequest{}).
+		Build()
+	reconciler := &CertificateRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(cr)
+	reconcileAndGet := func() *networkingv1.CertificateRequest {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		var got networkingv1.CertificateRequest
+		if err := fakeClient.Get(ctx, key, &got); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+	wantReplicated := func(got *networkingv1.CertificateRequest, status metav1.ConditionStatus, reason string, namespaces []string) {
+		t.Helper()
+		cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionSecretReplicated)
+		if cond == nil || cond.Status != status || cond.Reason != reason {
+			t.Errorf("SecretReplicated condition = %+v, want %s/%s", cond, status, reason)
+		}
+		if !reflect.DeepEqual(got.Status.ReplicatedNamespaces, namespaces) {
+			t.Errorf("ReplicatedNamespaces = %v, want %v", got.Status.ReplicatedNamespaces, namespaces)
+		}
+	}
+	wantCopy := func(namespace, data string) {
+		t.Helper()
+		var replica corev1.Secret
+		err := fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: testSecretName}, &replica)
+		if data == "" {
+			if !errors.IsNotFound(err) {
+				t.Errorf("expected no copy in %s, got %v", namespace, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("expected a copy in %s: %v", namespace, err)
+		}
+		if string(replica.Data["tls.crt"]) != data || replica.Type != corev1.SecretTypeTLS {
+			t.Errorf("copy in %s = %v %q, want %s %q", namespace, replica.Type, replica.Data["tls.crt"], corev1.SecretTypeTLS, data)
+		}
+	}
+
+	// Nothing is copied until cert-manager issues the Secret
+	wantReplicated(reconcileAndGet(), metav1.ConditionUnknown, networkingv1.ReasonWaitingForSecret, nil)
+
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testSecretName, Namespace: testNamespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{"tls.crt": []byte("first"), "tls.key": []byte("key")},
+	}
+	if err := fakeClient.Create(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+	got := reconcileAndGet()
+	wantReplicated(got, metav1.ConditionFalse, networkingv1.ReasonReplicationFailed, []string{"edge", "traefik"})
+	wantCopy("edge", "first")
+	wantCopy("traefik", "first")
+	// A namespace that has not opted in is reported and gets no copy
+	wantCopy("locked", "")
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionSecretReplicated); cond == nil ||
+		!strings.Contains(cond.Message, "namespace locked does not accept copies") {
+		t.Errorf("SecretReplicated condition = %+v, want the refusal of namespace locked", cond)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(foreign), foreign); err != nil || foreign.Labels[replicaOfLabel] != "" {
+		t.Errorf("expected the unrelated Secret in infra to be left alone, got %+v, %v", foreign.ObjectMeta, err)
+	}
+
+	// Renewal updates the copies; a namespace that stops matching loses its copy
+	source.Data["tls.crt"] = []byte("renewed")
+	if err := fakeClient.Update(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+	edge.Labels = nil
+	if err := fakeClient.Update(ctx, edge); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Delete(ctx, foreign); err != nil {
+		t.Fatal(err)
+	}
+	locked.Annotations = fromDefault
+	if err := fakeClient.Update(ctx, locked); err != nil {
+		t.Fatal(err)
+	}
+	wantReplicated(reconcileAndGet(), metav1.ConditionTrue, networkingv1.ReasonReplicated, []string{"infra", "locked", "traefik"})
+	wantCopy("edge", "")
+	wantCopy("infra", "renewed")
+	wantCopy("locked", "renewed")
+	wantCopy("traefik", "renewed")
+
+	// Withdrawing the opt-in removes the copy
+	locked.Annotations = nil
+	if err := fakeClient.Update(ctx, locked); err != nil {
+		t.Fatal(err)
+	}
+	wantReplicated(reconcileAndGet(), metav1.ConditionFalse, networkingv1.ReasonReplicationFailed, []string{"infra", "traefik"})
+	wantCopy("locked", "")
+
+	// Copies are removed with the request
+	if err := fakeClient.Delete(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	wantCopy("infra", "")
+	wantCopy("traefik", "")
+	wantCopy(testNamespace, "renewed")
+}
+
+// TestRequestForReplica validates Secret copies map back to their CertificateRequest
+func TestRequestForReplica(t *testing.T) {
+	reconciler := &CertificateRequestReconciler{}
+
+	replica := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:        testSecretName,
+		Namespace:   "traefik",
+		Annotations: map[string]string{replicaOfAnnotation: testNamespace + "/test-cert"},
+	}}
+	got := reconciler.requestForReplica(context.Background(), replica)
+	if len(got) != 1 || got[0].Namespace != testNamespace || got[0].Name != "test-cert" {
+		t.Errorf("requestForReplica = %v, want %s/test-cert", got, testNamespace)
+	}
+
+	if got := reconciler.requestForReplica(context.Background(), &corev1.Secret{}); len(got) != 0 {
+		t.Errorf("requestForReplica = %v, want none for a Secret without the annotation", got)
+	}
+}
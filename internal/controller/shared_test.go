@@ -16,6 +16,14 @@ limitations under the License.
 
 package controller
 
+import (
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
 const (
 	testNamespace     = "default"
 	testSecretName    = "test-secret"
@@ -29,3 +37,28 @@ const (
 	testSvcName       = "test-svc"
 	testTLSSecretName = "my-tls-secret"
 )
+
+// testDomainSources serves testDomainKey from memory for every source kind
+func testDomainSources() *utils.DomainSources {
+	static := utils.StaticDomainSource{testDomainKey: "example.com"}
+	return &utils.DomainSources{
+		DefaultKind: utils.DomainSourceVault,
+		DefaultPath: "homelab/domains",
+		Sources: map[string]utils.DomainSource{
+			utils.DomainSourceVault:     static,
+			utils.DomainSourceConfigMap: static,
+		},
+	}
+}
+
+// testClusterIssuer returns the default ClusterIssuer in the Ready state
+func testClusterIssuer() *certmanagerv1.ClusterIssuer {
+	return &certmanagerv1.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultIssuerName},
+		Status: certmanagerv1.IssuerStatus{
+			Conditions: []certmanagerv1.IssuerCondition{
+				{Type: certmanagerv1.IssuerConditionReady, Status: cmmeta.ConditionTrue},
+			},
+		},
+	}
+}
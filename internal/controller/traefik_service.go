@@ -0,0 +1,182 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	traefikv1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+const (
+	// traefikServiceKind is the Traefik service kind referencing a TraefikService instead of a Service
+	traefikServiceKind = "TraefikService"
+	// weightedServiceSuffix names the weighted TraefikService mirrored by the main one
+	weightedServiceSuffix = "-weighted"
+)
+
+// usesTraefikService reports whether the request's default target is a generated TraefikService.
+// TCP and UDP routes weigh their backends themselves.
+func usesTraefikService(ir *networkingv1.IngressRequest) bool {
+	return ingressProtocol(ir) == networkingv1.IngressProtocolHTTP && (len(ir.Spec.Backends) > 0 || ir.Spec.Mirror != nil)
+}
+
+// traefikServiceName returns the name of the TraefikService the routes of ir point at
+func traefikServiceName(ir *networkingv1.IngressRequest) string {
+	return ir.Name
+}
+
+// traefikServiceNames returns the names of every TraefikService the operator may generate for ir
+func traefikServiceNames(ir *networkingv1.IngressRequest) []string {
+	return []string{traefikServiceName(ir), ir.Name + weightedServiceSuffix}
+}
+
+// buildTraefikServices constructs the TraefikServices for the backends and mirror of ir.
+// The first one is the service the routes point at: the weighted round robin over the backends,
+// or the mirroring of the main target when a mirror is set.
+func buildTraefikServices(ir *networkingv1.IngressRequest, ports servicePorts) []*traefikv1alpha1.TraefikService {
+	if !usesTraefikService(ir) {
+		return nil
+	}
+
+	newService := func(name string) *traefikv1alpha1.TraefikService {
+		return &traefikv1alpha1.TraefikService{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ir.Namespace},
+		}
+	}
+
+	var weighted *traefikv1alpha1.TraefikService
+	if len(ir.Spec.Backends) > 0 {
+		name := traefikServiceName(ir)
+		if ir.Spec.Mirror != nil {
+			name = ir.Name + weightedServiceSuffix
+		}
+		weighted = newService(name)
+		weighted.Spec.Weighted = &traefikv1alpha1.WeightedRoundRobin{
+			Services: make([]traefikv1alpha1.Service, 0, len(ir.Spec.Backends)),
+		}
+		for _, backend := range ir.Spec.Backends {
+			weight := 1
+			if backend.Weight != nil {
+				weight = *backend.Weight
+			}
+			weighted.Spec.Weighted.Services = append(weighted.Spec.Weighted.Services, traefikv1alpha1.Service{
+				LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+					Name:   backend.ServiceName,
+					Port:   ports.port(backend.ServiceName, backend.ServicePort),
+					Weight: &weight,
+				},
+			})
+		}
+		if ir.Spec.Mirror == nil {
+			return []*traefikv1alpha1.TraefikService{weighted}
+		}
+	}
+
+	// The mirrored main target is the weighted service, or the spec's Service
+	main := traefikv1alpha1.LoadBalancerSpec{
+		Name: ir.Spec.ServiceName,
+		Port: ports.port(ir.Spec.ServiceName, ir.Spec.ServicePort),
+	}
+	if weighted != nil {
+		main = traefikv1alpha1.LoadBalancerSpec{Name: weighted.Name, Kind: traefikServiceKind}
+	}
+	percent := 100
+	if ir.Spec.Mirror.Percent != nil {
+		percent = *ir.Spec.Mirror.Percent
+	}
+	mirroring := newService(traefikServiceName(ir))
+	mirroring.Spec.Mirroring = &traefikv1alpha1.Mirroring{
+		LoadBalancerSpec: main,
+		Mirrors: []traefikv1alpha1.MirrorService{{
+			LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+				Name: ir.Spec.Mirror.ServiceName,
+				Port: ports.port(ir.Spec.Mirror.ServiceName, ir.Spec.Mirror.ServicePort),
+			},
+			Percent: percent,
+		}},
+	}
+
+	services := []*traefikv1alpha1.TraefikService{mirroring}
+	if weighted != nil {
+		services = append(services, weighted)
+	}
+	return services
+}
+
+// traefikServiceConflict returns a message naming a TraefikService ir would generate that
+// exists without being controlled by ir, or an empty string when there is none
+func (r *IngressRequestReconciler) traefikServiceConflict(ctx context.Context, ir *networkingv1.IngressRequest,
+	ports servicePorts) (string, error) {
+	for _, svc := range buildTraefikServices(ir, ports) {
+		var existing traefikv1alpha1.TraefikService
+		if err := r.Get(ctx, client.ObjectKeyFromObject(svc), &existing); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", fmt.Errorf("failed to get TraefikService %s: %w", svc.Name, err)
+		}
+		if !metav1.IsControlledBy(&existing, ir) {
+			return fmt.Sprintf("TraefikService %s exists and is not managed by this IngressRequest", svc.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// syncTraefikServices applies the TraefikServices of ir and deletes the ones it no longer needs
+func (r *IngressRequestReconciler) syncTraefikServices(ctx context.Context, ir *networkingv1.IngressRequest,
+	ports servicePorts) error {
+	desired := map[string]bool{}
+	for _, svc := range buildTraefikServices(ir, ports) {
+		desired[svc.Name] = true
+		if err := ctrl.SetControllerReference(ir, svc, r.Scheme); err != nil {
+			return fmt.Errorf("failed to set controller reference: %w", err)
+		}
+		if err := r.applyTraefikService(ctx, ir, svc); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range traefikServiceNames(ir) {
+		if desired[name] {
+			continue
+		}
+		if err := r.deleteTraefikService(ctx, ir, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTraefikService server-side applies svc, reporting manual changes to it as drift
+func (r *IngressRequestReconciler) applyTraefikService(ctx context.Context, ir *networkingv1.IngressRequest,
+	svc *traefikv1alpha1.TraefikService) error {
+	return r.applyGenerated(ctx, ir, svc, &traefikv1alpha1.TraefikService{}, "TraefikService",
+		func(obj client.Object) interface{} { return obj.(*traefikv1alpha1.TraefikService).Spec })
+}
+
+// deleteTraefikService deletes the TraefikService name when ir controls it
+func (r *IngressRequestReconciler) deleteTraefikService(ctx context.Context, ir *networkingv1.IngressRequest, name string) error {
+	return r.deleteControlled(ctx, ir, &traefikv1alpha1.TraefikService{}, name)
+}
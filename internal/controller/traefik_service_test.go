@@ -0,0 +1,275 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	traefikv1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// TestBuildTraefikServices validates the weighted and mirroring TraefikServices built for a request
+func TestBuildTraefikServices(t *testing.T) {
+	backends := []networkingv1.WeightedBackend{
+		{ServiceName: "stable", ServicePort: "80", Weight: intPtr(90)},
+		{ServiceName: "canary", Weight: intPtr(10)},
+	}
+	ports := servicePorts{{name: "canary"}: "8080"}
+
+	tests := []struct {
+		name      string
+		spec      networkingv1.IngressRequestSpec
+		wantNames []string
+	}{
+		{
+			name: "plain service",
+			spec: networkingv1.IngressRequestSpec{ServiceName: "stable"},
+		},
+		{
+			name:      "weighted backends",
+			spec:      networkingv1.IngressRequestSpec{Backends: backends},
+			wantNames: []string{"test-ingress"},
+		},
+		{
+			name: "mirrored service",
+			spec: networkingv1.IngressRequestSpec{
+				ServiceName: "stable",
+				ServicePort: "80",
+				Mirror:      &networkingv1.MirrorBackend{ServiceName: "shadow", ServicePort: "http", Percent: intPtr(20)},
+			},
+			wantNames: []string{"test-ingress"},
+		},
+		{
+			name: "mirrored weighted backends",
+			spec: networkingv1.IngressRequestSpec{
+				Backends: backends,
+				Mirror:   &networkingv1.MirrorBackend{ServiceName: "shadow", ServicePort: "http"},
+			},
+			wantNames: []string{"test-ingress", "test-ingress-weighted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := &networkingv1.IngressRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace},
+				Spec:       tt.spec,
+			}
+			services := buildTraefikServices(ir, ports)
+			if len(services) != len(tt.wantNames) {
+				t.Fatalf("built %d TraefikServices, want %v", len(services), tt.wantNames)
+			}
+			for i, svc := range services {
+				if svc.Name != tt.wantNames[i] || svc.Namespace != testNamespace {
+					t.Errorf("TraefikService %d = %s/%s, want %s", i, svc.Namespace, svc.Name, tt.wantNames[i])
+				}
+			}
+			if len(services) == 0 {
+				return
+			}
+
+			var weighted *traefikv1alpha1.WeightedRoundRobin
+			for _, svc := range services {
+				if svc.Spec.Weighted != nil {
+					weighted = svc.Spec.Weighted
+				}
+			}
+			if len(tt.spec.Backends) > 0 {
+				if weighted == nil || len(weighted.Services) != 2 {
+					t.Fatalf("weighted = %+v, want the two backends", weighted)
+				}
+				stable, canary := weighted.Services[0], weighted.Services[1]
+				if stable.Name != "stable" || stable.Port != intstr.FromInt32(80) || *stable.Weight != 90 {
+					t.Errorf("stable backend = %s:%s weight %d", stable.Name, stable.Port.String(), *stable.Weight)
+				}
+				if canary.Name != "canary" || canary.Port != intstr.FromInt32(8080) || *canary.Weight != 10 {
+					t.Errorf("canary backend = %s:%s weight %d", canary.Name, canary.Port.String(), *canary.Weight)
+				}
+			}
+
+			if tt.spec.Mirror != nil {
+				mirroring := services[0].Spec.Mirroring
+				if mirroring == nil || len(mirroring.Mirrors) != 1 || mirroring.Mirrors[0].Name != "shadow" {
+					t.Fatalf("mirroring = %+v, want a mirror to shadow", mirroring)
+				}
+				wantPercent := 100
+				if tt.spec.Mirror.Percent != nil {
+					wantPercent = *tt.spec.Mirror.Percent
+				}
+				if mirroring.Mirrors[0].Percent != wantPercent {
+					t.Errorf("mirror percent = %d, want %d", mirroring.Mirrors[0].Percent, wantPercent)
+				}
+				if len(tt.spec.Backends) > 0 {
+					if mirroring.Name != "test-ingress-weighted" || mirroring.Kind != traefikServiceKind {
+						t.Errorf("mirrored service = %s/%s, want the weighted TraefikService", mirroring.Kind, mirroring.Name)
+					}
+				} else if mirroring.Name != "stable" || mirroring.Port != intstr.FromInt32(80) {
+					t.Errorf("mirrored service = %s:%s, want stable:80", mirroring.Name, mirroring.Port.String())
+				}
+			}
+		})
+	}
+}
+
+// TestIngressRequestTraefikServices validates the generated TraefikServices follow the request,
+// are removed when no longer needed and are not taken over from others
+func TestIngressRequestTraefikServices(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = traefikv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	newService := func(name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: testServicePort, Port: 80}}},
+		}
+	}
+	ir := &networkingv1.IngressRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace, Generation: 1},
+		Spec: networkingv1.IngressRequestSpec{
+			Subdomain: testSubdomain,
+			DomainKey: testDomainKey,
+			Backends: []networkingv1.WeightedBackend{
+				{ServiceName: "stable", Weight: intPtr(90)},
+				{ServiceName: "canary", Weight: intPtr(10)},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(ir, newService("stable"), newService("canary"), newService("shadow")).
+		WithStatusSubresource(&networkingv1.IngressRequest{}).
+		Build()
+	reconciler := &IngressRequestReconciler{Client: fakeClient, Scheme: scheme, Domains: testDomainSources()}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(ir)
+	weightedKey := client.ObjectKey{Namespace: testNamespace, Name: "test-ingress-weighted"}
+	reconcile := func() {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+	}
+	getTraefikService := func(key client.ObjectKey) *traefikv1alpha1.TraefikService {
+		t.Helper()
+		var svc traefikv1alpha1.TraefikService
+		if err := fakeClient.Get(ctx, key, &svc); err != nil {
+			t.Fatalf("expected TraefikService %s: %v", key, err)
+		}
+		return &svc
+	}
+	updateSpec := func(update func(spec *networkingv1.IngressRequestSpec)) {
+		t.Helper()
+		var current networkingv1.IngressRequest
+		if err := fakeClient.Get(ctx, key, &current); err != nil {
+			t.Fatal(err)
+		}
+		update(&current.Spec)
+		if err := fakeClient.Update(ctx, &current); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reconcile()
+	svc := getTraefikService(key)
+	if owner := metav1.GetControllerOf(svc); owner == nil || owner.Kind != "IngressRequest" || owner.Name != ir.Name {
+		t.Errorf("owner references = %v, want the IngressRequest", svc.OwnerReferences)
+	}
+	if svc.Spec.Weighted == nil || *svc.Spec.Weighted.Services[1].Weight != 10 {
+		t.Fatalf("weighted = %+v, want canary at 10", svc.Spec.Weighted)
+	}
+	if svc.Spec.Weighted.Services[0].Port != intstr.FromInt32(80) {
+		t.Errorf("stable port = %s, want the Service's only port 80", svc.Spec.Weighted.Services[0].Port.String())
+	}
+	var route traefikv1alpha1.IngressRoute
+	if err := fakeClient.Get(ctx, key, &route); err != nil {
+		t.Fatal(err)
+	}
+	if target := route.Spec.Routes[0].Services[0]; target.Name != "test-ingress" || target.Kind != traefikServiceKind {
+		t.Errorf("route target = %s/%s, want TraefikService test-ingress", target.Kind, target.Name)
+	}
+
+	// Shifting weights updates the TraefikService
+	updateSpec(func(spec *networkingv1.IngressRequestSpec) { spec.Backends[1].Weight = intPtr(50) })
+	reconcile()
+	if weight := *getTraefikService(key).Spec.Weighted.Services[1].Weight; weight != 50 {
+		t.Errorf("canary weight = %d, want 50", weight)
+	}
+
+	// A mirror moves the weighted round robin behind a mirroring TraefikService
+	updateSpec(func(spec *networkingv1.IngressRequestSpec) {
+		spec.Mirror = &networkingv1.MirrorBackend{ServiceName: "shadow", Percent: intPtr(5)}
+	})
+	reconcile()
+	if mirroring := getTraefikService(key).Spec.Mirroring; mirroring == nil || mirroring.Name != weightedKey.Name {
+		t.Errorf("mirroring = %+v, want it to mirror %s", mirroring, weightedKey.Name)
+	}
+	if getTraefikService(weightedKey).Spec.Weighted == nil {
+		t.Error("expected the weighted TraefikService")
+	}
+
+	// Routing to a plain Service removes the generated TraefikServices
+	updateSpec(func(spec *networkingv1.IngressRequestSpec) {
+		spec.Backends, spec.Mirror = nil, nil
+		spec.ServiceName = "stable"
+	})
+	reconcile()
+	for _, key := range []client.ObjectKey{key, weightedKey} {
+		if err := fakeClient.Get(ctx, key, &traefikv1alpha1.TraefikService{}); !errors.IsNotFound(err) {
+			t.Errorf("expected TraefikService %s to be deleted, got %v", key, err)
+		}
+	}
+
+	// A TraefikService created by someone else is not taken over
+	foreign := &traefikv1alpha1.TraefikService{ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: testNamespace}}
+	if err := fakeClient.Create(ctx, foreign); err != nil {
+		t.Fatal(err)
+	}
+	updateSpec(func(spec *networkingv1.IngressRequestSpec) {
+		spec.ServiceName = ""
+		spec.Backends = []networkingv1.WeightedBackend{{ServiceName: "stable"}}
+	})
+	reconcile()
+	var got networkingv1.IngressRequest
+	if err := fakeClient.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionReady); cond == nil ||
+		cond.Reason != networkingv1.ReasonTraefikServiceConflict {
+		t.Errorf("Ready condition = %+v, want TraefikServiceConflict", cond)
+	}
+	if getTraefikService(key).Spec.Weighted != nil {
+		t.Error("the foreign TraefikService was overwritten")
+	}
+}
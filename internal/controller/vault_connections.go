@@ -0,0 +1,243 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	vault "github.com/hashicorp/vault/api"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+const (
+	vaultConnectionKind        = "VaultConnection"
+	clusterVaultConnectionKind = "ClusterVaultConnection"
+)
+
+// VaultConnections builds and caches Vault clients for VaultConnection and
+// ClusterVaultConnection resources. It implements utils.VaultClientProvider.
+type VaultConnections struct {
+	// Reader fetches connections and the Secrets they reference
+	Reader client.Reader
+
+	mu      sync.Mutex
+	clients map[string]*vaultConnectionClient
+}
+
+type vaultConnectionClient struct {
+	client      *vault.Client
+	fingerprint string
+	cancel      context.CancelFunc
+}
+
+// NewVaultConnections creates a VaultConnections reading through reader
+func NewVaultConnections(reader client.Reader) *VaultConnections {
+	return &VaultConnections{
+		Reader:  reader,
+		clients: map[string]*vaultConnectionClient{},
+	}
+}
+
+// vaultConnectionKey identifies the connection selected by ref for a request in namespace.
+// A nil ref selects the default connection, identified by an empty key.
+func vaultConnectionKey(namespace string, ref *networkingv1.VaultConnectionReference) string {
+	if ref == nil {
+		return ""
+	}
+	if ref.Kind == clusterVaultConnectionKind {
+		return clusterVaultConnectionKind + "/" + ref.Name
+	}
+	return vaultConnectionKind + "/" + namespace + "/" + ref.Name
+}
+
+// parseVaultConnectionKey splits a key built by vaultConnectionKey into its kind and object key
+func parseVaultConnectionKey(connection string) (string, types.NamespacedName, error) {
+	parts := strings.Split(connection, "/")
+	switch {
+	case len(parts) == 2 && parts[0] == clusterVaultConnectionKind && parts[1] != "":
+		return parts[0], types.NamespacedName{Name: parts[1]}, nil
+	case len(parts) == 3 && parts[0] == vaultConnectionKind && parts[1] != "" && parts[2] != "":
+		return parts[0], types.NamespacedName{Namespace: parts[1], Name: parts[2]}, nil
+	default:
+		return "", types.NamespacedName{}, fmt.Errorf("invalid Vault connection '%s'", connection)
+	}
+}
+
+// VaultClient implements utils.VaultClientProvider.
+// Clients are rebuilt when the connection or a Secret it references changes.
+func (c *VaultConnections) VaultClient(ctx context.Context, connection string) (*vault.Client, error) {
+	if connection == "" {
+		return utils.GetVaultClient()
+	}
+
+	kind, key, err := parseVaultConnectionKey(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, version, err := c.getConnection(ctx, kind, key)
+	if apierrors.IsNotFound(err) {
+		c.drop(connection)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, key, err)
+	}
+
+	cfg, secretVersion, err := c.connectionConfig(ctx, kind, key.Namespace, spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %s: %w", kind, key, err)
+	}
+	fingerprint := version + "/" + secretVersion
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.clients[connection]; ok && existing.fingerprint == fingerprint {
+		return existing.client, nil
+	}
+
+	// The renewal goroutine outlives the reconcile that created the client
+	clientCtx, cancel := context.WithCancel(context.Background())
+	vaultClient, err := utils.NewVaultClient(clientCtx, cfg)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to connect to Vault for %s %s: %w", kind, key, err)
+	}
+
+	if existing, ok := c.clients[connection]; ok {
+		existing.cancel()
+	}
+	if c.clients == nil {
+		c.clients = map[string]*vaultConnectionClient{}
+	}
+	c.clients[connection] = &vaultConnectionClient{client: vaultClient, fingerprint: fingerprint, cancel: cancel}
+
+	return vaultClient, nil
+}
+
+// drop forgets the client for connection and stops renewing its token
+func (c *VaultConnections) drop(connection string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.clients[connection]; ok {
+		existing.cancel()
+		delete(c.clients, connection)
+	}
+}
+
+// getConnection fetches the spec of the connection named by kind and key,
+// along with a version that changes whenever the spec does
+func (c *VaultConnections) getConnection(ctx context.Context, kind string,
+	key types.NamespacedName) (networkingv1.VaultConnectionSpec, string, error) {
+	if kind == clusterVaultConnectionKind {
+		var conn networkingv1.ClusterVaultConnection
+		if err := c.Reader.Get(ctx, key, &conn); err != nil {
+			return networkingv1.VaultConnectionSpec{}, "", err
+		}
+		return conn.Spec, fmt.Sprintf("%s/%d", conn.UID, conn.Generation), nil
+	}
+
+	var conn networkingv1.VaultConnection
+	if err := c.Reader.Get(ctx, key, &conn); err != nil {
+		return networkingv1.VaultConnectionSpec{}, "", err
+	}
+	return conn.Spec, fmt.Sprintf("%s/%d", conn.UID, conn.Generation), nil
+}
+
+// connectionConfig builds the client configuration for spec, reading the Secret it references.
+// It also returns the resource version of the Secret it read, if any.
+func (c *VaultConnections) connectionConfig(ctx context.Context, kind, namespace string,
+	spec networkingv1.VaultConnectionSpec) (utils.VaultConnectionConfig, string, error) {
+	auth := spec.Auth
+	cfg := utils.VaultConnectionConfig{
+		Address:   spec.Address,
+		CABundle:  spec.CABundle,
+		Namespace: spec.Namespace,
+		Auth: utils.VaultAuthConfig{
+			Method:    auth.Method,
+			MountPath: auth.MountPath,
+		},
+	}
+	if cfg.Auth.MountPath == "" {
+		cfg.Auth.MountPath = auth.Method
+	}
+
+	var secretVersion string
+	var err error
+	switch auth.Method {
+	case utils.VaultAuthToken:
+		if auth.TokenSecretRef == nil {
+			return cfg, "", fmt.Errorf("tokenSecretRef is required for token auth")
+		}
+		cfg.Token, secretVersion, err = c.secretValue(ctx, kind, namespace, *auth.TokenSecretRef)
+	case utils.VaultAuthKubernetes:
+		if auth.Kubernetes == nil {
+			return cfg, "", fmt.Errorf("kubernetes is required for kubernetes auth")
+		}
+		// The operator's token must not be sent to an address chosen in a tenant namespace
+		if kind != clusterVaultConnectionKind {
+			return cfg, "", fmt.Errorf("kubernetes auth is only allowed on a %s", clusterVaultConnectionKind)
+		}
+		cfg.Auth.Role = auth.Kubernetes.Role
+		cfg.Auth.TokenPath = utils.VaultAuthConfigFromEnv().TokenPath
+	case utils.VaultAuthAppRole:
+		if auth.AppRole == nil {
+			return cfg, "", fmt.Errorf("appRole is required for approle auth")
+		}
+		cfg.Auth.RoleID = auth.AppRole.RoleID
+		cfg.Auth.SecretID, secretVersion, err = c.secretValue(ctx, kind, namespace, auth.AppRole.SecretIDRef)
+	default:
+		return cfg, "", fmt.Errorf("unsupported Vault auth method '%s'", auth.Method)
+	}
+
+	return cfg, secretVersion, err
+}
+
+// secretValue reads a key from the Secret referenced by ref. A VaultConnection
+// reads from its own namespace; a ClusterVaultConnection uses ref.Namespace.
+func (c *VaultConnections) secretValue(ctx context.Context, kind, namespace string,
+	ref networkingv1.SecretKeyRef) (string, string, error) {
+	if kind == clusterVaultConnectionKind {
+		namespace = ref.Namespace
+	}
+	if namespace == "" {
+		return "", "", fmt.Errorf("namespace is required for Secret %s referenced by a %s", ref.Name, kind)
+	}
+
+	var secret corev1.Secret
+	key := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+	if err := c.Reader.Get(ctx, key, &secret); err != nil {
+		return "", "", fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok || len(value) == 0 {
+		return "", "", fmt.Errorf("key '%s' not found in Secret %s", ref.Key, key)
+	}
+
+	return strings.TrimSpace(string(value)), secret.ResourceVersion, nil
+}
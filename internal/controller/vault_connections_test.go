@@ -0,0 +1,182 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+// TestVaultConnectionKey validates that connection keys round-trip through parsing
+func TestVaultConnectionKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      *networkingv1.VaultConnectionReference
+		want     string
+		wantKind string
+	}{
+		{
+			name: "default",
+			ref:  nil,
+			want: "",
+		},
+		{
+			name:     "namespaced",
+			ref:      &networkingv1.VaultConnectionReference{Kind: "VaultConnection", Name: "lab"},
+			want:     "VaultConnection/default/lab",
+			wantKind: vaultConnectionKind,
+		},
+		{
+			name:     "namespaced without kind",
+			ref:      &networkingv1.VaultConnectionReference{Name: "lab"},
+			want:     "VaultConnection/default/lab",
+			wantKind: vaultConnectionKind,
+		},
+		{
+			name:     "cluster",
+			ref:      &networkingv1.VaultConnectionReference{Kind: "ClusterVaultConnection", Name: "lab"},
+			want:     "ClusterVaultConnection/lab",
+			wantKind: clusterVaultConnectionKind,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := vaultConnectionKey(testNamespace, tt.ref)
+			if got != tt.want {
+				t.Fatalf("vaultConnectionKey = %v, want %v", got, tt.want)
+			}
+			if got == "" {
+				return
+			}
+
+			kind, key, err := parseVaultConnectionKey(got)
+			if err != nil {
+				t.Fatalf("parseVaultConnectionKey returned error: %v", err)
+			}
+			if kind != tt.wantKind || key.Name != tt.ref.Name {
+				t.Errorf("parseVaultConnectionKey = %v %v, want %v %v", kind, key, tt.wantKind, tt.ref.Name)
+			}
+		})
+	}
+
+	if _, _, err := parseVaultConnectionKey("Vault/lab"); err == nil {
+		t.Error("Expected error for an invalid connection key")
+	}
+}
+
+// TestVaultConnectionsClient validates clients are built from connections and rebuilt when their Secret changes
+func TestVaultConnectionsClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault-token", Namespace: "vault"},
+		Data:       map[string][]byte{"token": []byte("s.first\n")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			&networkingv1.ClusterVaultConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: "lab"},
+				Spec: networkingv1.VaultConnectionSpec{
+					Address:   "https://vault.lab.example.com:8200",
+					Namespace: "homelab",
+					Auth: networkingv1.VaultAuth{
+						Method:         "token",
+						TokenSecretRef: &networkingv1.SecretKeyRef{Name: "vault-token", Namespace: "vault", Key: "token"},
+					},
+				},
+			},
+			&networkingv1.VaultConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: "lab", Namespace: testNamespace},
+				Spec: networkingv1.VaultConnectionSpec{
+					Address: "https://vault.lab.example.com:8200",
+					Auth: networkingv1.VaultAuth{
+						Method:         "token",
+						TokenSecretRef: &networkingv1.SecretKeyRef{Name: "vault-token", Namespace: "vault", Key: "token"},
+					},
+				},
+			},
+			&networkingv1.VaultConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: "kube", Namespace: testNamespace},
+				Spec: networkingv1.VaultConnectionSpec{
+					Address: "https://vault.attacker.example.com:8200",
+					Auth: networkingv1.VaultAuth{
+						Method:     "kubernetes",
+						Kubernetes: &networkingv1.VaultKubernetesAuth{Role: "homelab-alm"},
+					},
+				},
+			},
+			tokenSecret,
+		).Build()
+
+	connections := NewVaultConnections(fakeClient)
+	ctx := context.Background()
+
+	first, err := connections.VaultClient(ctx, "ClusterVaultConnection/lab")
+	if err != nil {
+		t.Fatalf("VaultClient returned error: %v", err)
+	}
+	if first.Address() != "https://vault.lab.example.com:8200" || first.Namespace() != "homelab" {
+		t.Errorf("client = %v in namespace %v, want https://vault.lab.example.com:8200 in homelab", first.Address(), first.Namespace())
+	}
+	if first.Token() != "s.first" {
+		t.Errorf("Token = %v, want s.first", first.Token())
+	}
+
+	again, err := connections.VaultClient(ctx, "ClusterVaultConnection/lab")
+	if err != nil {
+		t.Fatalf("VaultClient returned error: %v", err)
+	}
+	if again != first {
+		t.Error("expected the client to be reused while the connection is unchanged")
+	}
+
+	tokenSecret.Data["token"] = []byte("s.second")
+	if err := fakeClient.Update(ctx, tokenSecret); err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := connections.VaultClient(ctx, "ClusterVaultConnection/lab")
+	if err != nil {
+		t.Fatalf("VaultClient returned error: %v", err)
+	}
+	if rotated.Token() != "s.second" {
+		t.Errorf("Token after rotation = %v, want s.second", rotated.Token())
+	}
+
+	// A namespaced connection only reads Secrets from its own namespace
+	if _, err := connections.VaultClient(ctx, "VaultConnection/default/lab"); err == nil {
+		t.Error("Expected error for a VaultConnection referencing a Secret in another namespace")
+	}
+
+	// The operator's service account token is never sent for a namespaced connection
+	if _, err := connections.VaultClient(ctx, "VaultConnection/default/kube"); err == nil {
+		t.Error("Expected error for a VaultConnection using kubernetes auth")
+	}
+
+	if _, err := connections.VaultClient(ctx, "ClusterVaultConnection/missing"); err == nil {
+		t.Error("Expected error for a missing connection")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	vault "github.com/hashicorp/vault/api"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+// DefaultVaultPKIMount is the PKI mount used when neither the request nor the operator sets one
+const DefaultVaultPKIMount = "pki"
+
+// VaultPKI configures the VaultPKI backend, which issues from a Vault PKI secrets engine
+type VaultPKI struct {
+	// Clients provides the Vault client of a request's vaultConnection; the environment's when nil
+	Clients utils.VaultClientProvider
+	// Mount is used when a request does not set spec.vaultPKI.mount; DefaultVaultPKIMount when empty
+	Mount string
+	// Role is used when a request does not set spec.vaultPKI.role.
+	// A request can only set spec.vaultPKI together with its own VaultConnection.
+	Role string
+}
+
+// vaultPKIIssuer issues the certificates of one request from a PKI role
+type vaultPKIIssuer struct {
+	client *vault.Client
+	mount  string
+	role   string
+}
+
+// issuerFor returns the issuer for cr, resolving its mount, role and Vault connection.
+// The operator's connections are only used with the operator's mount and role.
+func (p *VaultPKI) issuerFor(ctx context.Context, cr *networkingv1.CertificateRequest) (*vaultPKIIssuer, error) {
+	var ref networkingv1.VaultPKIReference
+	if cr.Spec.VaultPKI != nil {
+		if !usesOwnVaultConnection(cr.Spec.VaultConnection) {
+			return nil, fmt.Errorf("vaultPKI can only be set with a %s in the request's namespace", vaultConnectionKind)
+		}
+		ref = *cr.Spec.VaultPKI
+	}
+	role := firstNonEmpty(ref.Role, p.Role)
+	if role == "" {
+		return nil, fmt.Errorf("no Vault PKI role set on the request or the operator")
+	}
+
+	client, err := utils.VaultClientFor(ctx, p.Clients, vaultConnectionKey(cr.Namespace, cr.Spec.VaultConnection))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Vault client: %w", err)
+	}
+
+	return &vaultPKIIssuer{
+		client: client,
+		mount:  firstNonEmpty(ref.Mount, p.Mount, DefaultVaultPKIMount),
+		role:   role,
+	}, nil
+}
+
+// usesOwnVaultConnection reports whether ref selects a VaultConnection in the request's namespace
+// rather than the default connection or a ClusterVaultConnection
+func usesOwnVaultConnection(ref *networkingv1.VaultConnectionReference) bool {
+	return ref != nil && ref.Kind != clusterVaultConnectionKind
+}
+
+// reference implements directIssuer
+func (i *vaultPKIIssuer) reference() string {
+	return fmt.Sprintf("%s %s/issue/%s", networkingv1.CertificateBackendVaultPKI, strings.Trim(i.mount, "/"), i.role)
+}
+
+// caPEM implements directIssuer, returning the root of the mount's CA chain
+func (i *vaultPKIIssuer) caPEM(ctx context.Context) ([]byte, error) {
+	chain, err := utils.ReadVaultPKICAChain(ctx, i.client, i.mount)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := pki.ParseSingleCertificateChainPEM([]byte(chain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA chain of %s: %w", i.mount, err)
+	}
+	return bundle.CAPEM, nil
+}
+
+// issue implements directIssuer. The key type, subject and usages come from the role;
+// the names, lifetime and key encoding come from cert.
+func (i *vaultPKIIssuer) issue(ctx context.Context, cert *certmanagerv1.Certificate) (*issuedCertificate, error) {
+	req := utils.VaultPKIIssueRequest{CommonName: cert.Spec.CommonName}
+	for _, name := range cert.Spec.DNSNames {
+		if name != cert.Spec.CommonName {
+			req.AltNames = append(req.AltNames, name)
+		}
+	}
+	if cert.Spec.Duration != nil {
+		req.TTL = cert.Spec.Duration.Duration
+	}
+	if key := cert.Spec.PrivateKey; key != nil && key.Encoding == certmanagerv1.PKCS8 {
+		req.PrivateKeyFormat = "pkcs8"
+	}
+
+	issued, err := utils.IssueVaultPKI(ctx, i.client, i.mount, i.role, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the chain the same way cert-manager does: intermediates stay in tls.crt, the root goes to ca.crt
+	chain := strings.Join(append([]string{issued.Certificate}, issued.CAChain...), "\n")
+	bundle, err := pki.ParseSingleCertificateChainPEM([]byte(chain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate chain issued by %s: %w", i.mount, err)
+	}
+
+	return &issuedCertificate{ChainPEM: bundle.ChainPEM, PrivateKeyPEM: []byte(issued.PrivateKey), CAPEM: bundle.CAPEM}, nil
+}
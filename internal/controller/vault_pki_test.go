@@ -0,0 +1,315 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	vault "github.com/hashicorp/vault/api"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+)
+
+// fakeVaultPKI serves the issue and ca_chain endpoints of a PKI mount, signing with a generated CA
+type fakeVaultPKI struct {
+	mu       sync.Mutex
+	pair     *caKeyPair
+	mount    string
+	requests []map[string]interface{}
+}
+
+func newFakeVaultPKI(t *testing.T, mount string) (*fakeVaultPKI, *httptest.Server) {
+	t.Helper()
+	fakePKI := &fakeVaultPKI{mount: mount}
+	fakePKI.rotate(t)
+	server := httptest.NewServer(fakePKI)
+	t.Cleanup(server.Close)
+	return fakePKI, server
+}
+
+// rotate replaces the CA of the mount
+func (f *fakeVaultPKI) rotate(t *testing.T) {
+	t.Helper()
+	certPEM, keyPEM, err := generateCA(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := parseCAKeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pair = pair
+}
+
+func (f *fakeVaultPKI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/v1/"+f.mount+"/cert/ca_chain":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"certificate": string(f.pair.caPEM)},
+		})
+	case strings.HasPrefix(r.URL.Path, "/v1/"+f.mount+"/issue/"):
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.requests = append(f.requests, body)
+
+		cert := &certmanagerv1.Certificate{}
+		cert.Spec.CommonName, _ = body["common_name"].(string)
+		cert.Spec.DNSNames = []string{cert.Spec.CommonName}
+		if altNames, _ := body["alt_names"].(string); altNames != "" {
+			cert.Spec.DNSNames = append(cert.Spec.DNSNames, strings.Split(altNames, ",")...)
+		}
+		if ttl, _ := body["ttl"].(string); ttl != "" {
+			duration, _ := time.ParseDuration(ttl)
+			cert.Spec.Duration = &metav1.Duration{Duration: duration}
+		}
+		issued, err := signCertificate(f.pair, cert)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"certificate": string(issued.ChainPEM),
+				"private_key": string(issued.PrivateKeyPEM),
+				"ca_chain":    []string{string(f.pair.caPEM)},
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// lastRequest returns the parameters of the latest issue call
+func (f *fakeVaultPKI) lastRequest() map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return nil
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+// staticVaultClients returns the same client for every connection
+type staticVaultClients struct {
+	client      *vault.Client
+	connections []string
+}
+
+func (s *staticVaultClients) VaultClient(_ context.Context, connection string) (*vault.Client, error) {
+	s.connections = append(s.connections, connection)
+	return s.client, nil
+}
+
+// TestCertificateRequestVaultPKI validates the VaultPKI backend issues the TLS Secret from the
+// role of the request, keeps it while it is current and reissues it when the role changes
+func TestCertificateRequestVaultPKI(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	fakePKI, server := newFakeVaultPKI(t, "pki_int")
+	config := vault.DefaultConfig()
+	config.Address = server.URL
+	vaultClient, err := vault.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients := &staticVaultClients{client: vaultClient}
+
+	cr := &networkingv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cert", Namespace: testNamespace},
+		Spec: networkingv1.CertificateRequestSpec{
+			SecretName:      testSecretName,
+			DomainKey:       testDomainKey,
+			Subdomain:       testSubdomain,
+			DNSNames:        []networkingv1.CertificateDNSName{{Subdomain: "www." + testSubdomain}},
+			Backend:         networkingv1.CertificateBackendVaultPKI,
+			VaultPKI:        &networkingv1.VaultPKIReference{Mount: "pki_int"},
+			VaultConnection: &networkingv1.VaultConnectionReference{Kind: "VaultConnection", Name: "lab"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cr).
+		WithStatusSubresource(&networkingv1.CertificateRequest{}).
+		Build()
+	reconciler := &CertificateRequestReconciler{
+		Client:   fakeClient,
+		Scheme:   scheme,
+		Domains:  testDomainSources(),
+		VaultPKI: &VaultPKI{Clients: clients, Role: "web"},
+	}
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(cr)
+	secretKey := client.ObjectKey{Namespace: testNamespace, Name: testSecretName}
+	reconcileAndGetSecret := func() *corev1.Secret {
+		t.Helper()
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+		if result.RequeueAfter <= 0 {
+			t.Errorf("expected a requeue for renewal, got %v", result)
+		}
+		var secret corev1.Secret
+		if err := fakeClient.Get(ctx, secretKey, &secret); err != nil {
+			t.Fatalf("expected TLS Secret: %v", err)
+		}
+		return &secret
+	}
+
+	secret := reconcileAndGetSecret()
+	if got := fakePKI.lastRequest(); got["common_name"] != testFQDN || got["alt_names"] != "www."+testFQDN {
+		t.Errorf("issue request = %v, want common name %s", got, testFQDN)
+	}
+	if len(clients.connections) == 0 || clients.connections[0] != "VaultConnection/default/lab" {
+		t.Errorf("Vault connections = %v, want VaultConnection/default/lab", clients.connections)
+	}
+	leaf, err := pki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(secret.Data[cmmeta.TLSCAKey])
+	for _, name := range []string{testFQDN, "www." + testFQDN} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: name, Roots: roots}); err != nil {
+			t.Errorf("issued certificate does not verify for %s against ca.crt: %v", name, err)
+		}
+	}
+
+	var got networkingv1.CertificateRequest
+	if err := fakeClient.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Status.Ready || got.Status.Backend != networkingv1.CertificateBackendVaultPKI {
+		t.Errorf("status ready = %v, backend = %v", got.Status.Ready, got.Status.Backend)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, networkingv1.ConditionCertificateIssued); cond == nil ||
+		cond.Status != metav1.ConditionTrue || !strings.Contains(cond.Message, "pki_int/issue/web") {
+		t.Errorf("CertificateIssued condition = %+v, want True from pki_int/issue/web", cond)
+	}
+
+	// A current certificate is kept
+	issued := secret.Data[corev1.TLSCertKey]
+	if secret := reconcileAndGetSecret(); !bytes.Equal(secret.Data[corev1.TLSCertKey], issued) {
+		t.Error("certificate was reissued without changes")
+	}
+
+	// A role change reissues
+	got.Spec.VaultPKI.Role = "internal"
+	if err := fakeClient.Update(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	if secret := reconcileAndGetSecret(); bytes.Equal(secret.Data[corev1.TLSCertKey], issued) {
+		t.Error("certificate was not reissued after the role changed")
+	}
+
+	// A new CA reissues
+	issued = reconcileAndGetSecret().Data[corev1.TLSCertKey]
+	fakePKI.rotate(t)
+	if secret := reconcileAndGetSecret(); bytes.Equal(secret.Data[corev1.TLSCertKey], issued) {
+		t.Error("certificate was not reissued after the CA changed")
+	}
+}
+
+// TestVaultPKIIssuerFor validates mount and role resolution
+func TestVaultPKIIssuerFor(t *testing.T) {
+	clients := &staticVaultClients{client: &vault.Client{}}
+	own := &networkingv1.VaultConnectionReference{Kind: "VaultConnection", Name: "team"}
+	tests := []struct {
+		name       string
+		backend    VaultPKI
+		ref        *networkingv1.VaultPKIReference
+		connection *networkingv1.VaultConnectionReference
+		wantRef    string
+		wantError  bool
+	}{
+		{
+			name:    "operator defaults",
+			backend: VaultPKI{Clients: clients, Role: "web"},
+			wantRef: "VaultPKI pki/issue/web",
+		},
+		{
+			name:       "request overrides with its own connection",
+			backend:    VaultPKI{Clients: clients, Mount: "pki", Role: "web"},
+			ref:        &networkingv1.VaultPKIReference{Mount: "/pki_int/", Role: "internal"},
+			connection: own,
+			wantRef:    "VaultPKI pki_int/issue/internal",
+		},
+		{
+			name:      "request overrides with the default connection",
+			backend:   VaultPKI{Clients: clients, Mount: "pki", Role: "web"},
+			ref:       &networkingv1.VaultPKIReference{Mount: "pki_int", Role: "internal"},
+			wantError: true,
+		},
+		{
+			name:       "request overrides with a ClusterVaultConnection",
+			backend:    VaultPKI{Clients: clients, Mount: "pki", Role: "web"},
+			ref:        &networkingv1.VaultPKIReference{Role: "internal"},
+			connection: &networkingv1.VaultConnectionReference{Kind: "ClusterVaultConnection", Name: "lab"},
+			wantError:  true,
+		},
+		{
+			name:       "no role",
+			backend:    VaultPKI{Clients: clients},
+			ref:        &networkingv1.VaultPKIReference{Mount: "pki_int"},
+			connection: own,
+			wantError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &networkingv1.CertificateRequest{Spec: networkingv1.CertificateRequestSpec{
+				VaultPKI:        tt.ref,
+				VaultConnection: tt.connection,
+			}}
+			issuer, err := tt.backend.issuerFor(context.Background(), cr)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("issuerFor error = %v, wantError %v", err, tt.wantError)
+			}
+			if err == nil && issuer.reference() != tt.wantRef {
+				t.Errorf("reference = %q, want %q", issuer.reference(), tt.wantRef)
+			}
+		})
+	}
+}
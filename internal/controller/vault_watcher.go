@@ -0,0 +1,224 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+)
+
+// VaultWatcher polls the Vault secrets referenced by CertificateRequests and
+// IngressRequests and enqueues the requests whose domain key changed
+type VaultWatcher struct {
+	Client   client.Reader
+	Domains  *utils.DomainSources
+	Interval time.Duration
+	// Read fetches a KV secret, bypassing the cache
+	Read func(ctx context.Context, ref VaultSecretRef) (*utils.VaultKVSecret, error)
+
+	certificateRequests chan event.GenericEvent
+	ingressRequests     chan event.GenericEvent
+	seen                map[VaultSecretRef]*utils.VaultKVSecret
+}
+
+// NewVaultWatcher creates a VaultWatcher polling every interval, reading through clients
+func NewVaultWatcher(reader client.Reader, domains *utils.DomainSources, clients utils.VaultClientProvider,
+	interval time.Duration) *VaultWatcher {
+	return &VaultWatcher{
+		Client:              reader,
+		Domains:             domains,
+		Interval:            interval,
+		Read:                VaultReader(clients),
+		certificateRequests: make(chan event.GenericEvent),
+		ingressRequests:     make(chan event.GenericEvent),
+		seen:                map[VaultSecretRef]*utils.VaultKVSecret{},
+	}
+}
+
+// VaultReader returns a function reading KV secrets through clients, bypassing the cache
+func VaultReader(clients utils.VaultClientProvider) func(ctx context.Context, ref VaultSecretRef) (*utils.VaultKVSecret, error) {
+	return func(ctx context.Context, ref VaultSecretRef) (*utils.VaultKVSecret, error) {
+		vaultClient, err := utils.VaultClientFor(ctx, clients, ref.Connection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Vault client: %w", err)
+		}
+		return utils.ReadVaultKV(ctx, vaultClient, ref.Path)
+	}
+}
+
+// CertificateRequestSource returns the source of CertificateRequest events
+func (w *VaultWatcher) CertificateRequestSource() source.Source {
+	return source.Channel(w.certificateRequests, &handler.EnqueueRequestForObject{})
+}
+
+// IngressRequestSource returns the source of IngressRequest events
+func (w *VaultWatcher) IngressRequestSource() source.Source {
+	return source.Channel(w.ingressRequests, &handler.EnqueueRequestForObject{})
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (w *VaultWatcher) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable and polls until ctx is cancelled
+func (w *VaultWatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads every referenced Vault secret once and enqueues the requests
+// reading keys that changed since the previous version
+func (w *VaultWatcher) poll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("vault-watcher")
+
+	refs, err := w.referencedSecrets(ctx)
+	if err != nil {
+		logger.Error(err, "failed to list requests referencing Vault")
+		return
+	}
+
+	for ref := range w.seen {
+		if !refs[ref] {
+			delete(w.seen, ref)
+		}
+	}
+
+	for ref := range refs {
+		secret, err := w.Read(ctx, ref)
+		if err != nil {
+			logger.Error(err, "failed to read Vault path", "connection", ref.Connection, "path", ref.Path)
+			continue
+		}
+
+		previous, ok := w.seen[ref]
+		w.seen[ref] = secret
+		if !ok || (secret.Version != 0 && secret.Version == previous.Version) {
+			continue
+		}
+
+		// Make the reconciles below see the new version instead of a cached one
+		utils.InvalidateVaultCache(ref.Connection, ref.Path)
+
+		for _, key := range changedKeys(previous.Data, secret.Data) {
+			logger.Info("Vault domain changed", "connection", ref.Connection, "path", ref.Path,
+				"domainKey", key, "version", secret.Version)
+			if err := w.enqueue(ctx, vaultDomainIndexValue(ref, key)); err != nil {
+				logger.Error(err, "failed to enqueue requests", "connection", ref.Connection, "path", ref.Path, "domainKey", key)
+			}
+		}
+	}
+}
+
+// referencedSecrets returns the Vault secrets read by any request
+func (w *VaultWatcher) referencedSecrets(ctx context.Context) (map[VaultSecretRef]bool, error) {
+	refs := map[VaultSecretRef]bool{}
+
+	var certs networkingv1.CertificateRequestList
+	if err := w.Client.List(ctx, &certs); err != nil {
+		return nil, err
+	}
+	for i := range certs.Items {
+		if ref, ok := vaultLookup(w.Domains, certificateDomainRequest(&certs.Items[i])); ok {
+			refs[ref] = true
+		}
+	}
+
+	var ingresses networkingv1.IngressRequestList
+	if err := w.Client.List(ctx, &ingresses); err != nil {
+		return nil, err
+	}
+	for i := range ingresses.Items {
+		if ref, ok := vaultLookup(w.Domains, ingressDomainRequest(&ingresses.Items[i])); ok {
+			refs[ref] = true
+		}
+	}
+
+	return refs, nil
+}
+
+// enqueue sends an event for every request indexed under value
+func (w *VaultWatcher) enqueue(ctx context.Context, value string) error {
+	var certs networkingv1.CertificateRequestList
+	if err := w.Client.List(ctx, &certs, client.MatchingFields{vaultDomainIndex: value}); err != nil {
+		return err
+	}
+	for i := range certs.Items {
+		if !send(ctx, w.certificateRequests, &certs.Items[i]) {
+			return ctx.Err()
+		}
+	}
+
+	var ingresses networkingv1.IngressRequestList
+	if err := w.Client.List(ctx, &ingresses, client.MatchingFields{vaultDomainIndex: value}); err != nil {
+		return err
+	}
+	for i := range ingresses.Items {
+		if !send(ctx, w.ingressRequests, &ingresses.Items[i]) {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// send delivers a generic event for obj unless ctx is cancelled first
+func send(ctx context.Context, ch chan<- event.GenericEvent, obj client.Object) bool {
+	select {
+	case ch <- event.GenericEvent{Object: obj}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// changedKeys returns the keys added, removed or modified between two secret versions
+func changedKeys(previous, current map[string]interface{}) []string {
+	var keys []string
+	for key, val := range current {
+		if old, ok := previous[key]; !ok || !reflect.DeepEqual(old, val) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	networkingv1 "github.com/floryn08/homelab-alm/api/v1"
+	"github.com/floryn08/homelab-alm/internal/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// TestVaultWatcherPoll validates that only requests reading a changed key are enqueued
+func TestVaultWatcherPoll(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&networkingv1.CertificateRequest{}, vaultDomainIndex, func(obj client.Object) []string {
+			cr := obj.(*networkingv1.CertificateRequest)
+			return vaultDomainIndexValues(nil, certificateDomainRequest(cr))
+		}).
+		WithIndex(&networkingv1.IngressRequest{}, vaultDomainIndex, func(obj client.Object) []string {
+			ir := obj.(*networkingv1.IngressRequest)
+			return vaultDomainIndexValues(nil, ingressDomainRequest(ir))
+		}).
+		WithObjects(
+			&networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod-cert", Namespace: testNamespace},
+				Spec:       networkingv1.CertificateRequestSpec{DomainKey: testDomainKey},
+			},
+			&networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "lab-cert", Namespace: testNamespace},
+				Spec: networkingv1.CertificateRequestSpec{
+					DomainKey:       testDomainKey,
+					VaultConnection: &networkingv1.VaultConnectionReference{Kind: "ClusterVaultConnection", Name: "lab"},
+				},
+			},
+			&networkingv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "staging-cert", Namespace: testNamespace},
+				Spec:       networkingv1.CertificateRequestSpec{DomainKey: "stagingDomain"},
+			},
+			&networkingv1.IngressRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod-ingress", Namespace: testNamespace},
+				Spec:       networkingv1.IngressRequestSpec{DomainKey: testDomainKey},
+			},
+			&networkingv1.IngressRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-ingress", Namespace: testNamespace},
+				Spec: networkingv1.IngressRequestSpec{
+					DomainKey:    testDomainKey,
+					DomainSource: &networkingv1.DomainSourceRef{Kind: "ConfigMap", Name: "domains"},
+				},
+			},
+		).Build()
+
+	secret := &utils.VaultKVSecret{
+		Data:    map[string]interface{}{testDomainKey: "example.com", "stagingDomain": "staging.example.com"},
+		Version: 1,
+	}
+	labSecret := &utils.VaultKVSecret{Data: map[string]interface{}{testDomainKey: "lab.example.com"}, Version: 1}
+	reads := 0
+
+	watcher := NewVaultWatcher(fakeClient, nil, nil, time.Minute)
+	watcher.Read = func(_ context.Context, ref VaultSecretRef) (*utils.VaultKVSecret, error) {
+		reads++
+		if ref.Path != defaultVaultPath {
+			t.Errorf("read path = %v, want %v", ref.Path, defaultVaultPath)
+		}
+		if ref.Connection == "ClusterVaultConnection/lab" {
+			return labSecret, nil
+		}
+		return secret, nil
+	}
+	watcher.certificateRequests = make(chan event.GenericEvent, 10)
+	watcher.ingressRequests = make(chan event.GenericEvent, 10)
+
+	ctx := context.Background()
+
+	// First poll only records the current version
+	watcher.poll(ctx)
+	if reads != 2 {
+		t.Errorf("reads = %d, want one per connection", reads)
+	}
+	if len(watcher.certificateRequests)+len(watcher.ingressRequests) != 0 {
+		t.Error("first poll should not enqueue requests")
+	}
+
+	// Same version does not enqueue
+	watcher.poll(ctx)
+	if len(watcher.certificateRequests)+len(watcher.ingressRequests) != 0 {
+		t.Error("unchanged version should not enqueue requests")
+	}
+
+	secret = &utils.VaultKVSecret{
+		Data:    map[string]interface{}{testDomainKey: "new.example.com", "stagingDomain": "staging.example.com"},
+		Version: 2,
+	}
+	watcher.poll(ctx)
+
+	if got := drainNames(watcher.certificateRequests); len(got) != 1 || got[0] != "prod-cert" {
+		t.Errorf("enqueued CertificateRequests = %v, want [prod-cert]", got)
+	}
+	if got := drainNames(watcher.ingressRequests); len(got) != 1 || got[0] != "prod-ingress" {
+		t.Errorf("enqueued IngressRequests = %v, want [prod-ingress]", got)
+	}
+}
+
+// TestChangedKeys validates key diffing between secret versions
+func TestChangedKeys(t *testing.T) {
+	previous := map[string]interface{}{"a": "1", "b": "2", "c": "3"}
+	current := map[string]interface{}{"a": "1", "b": "changed", "d": "4"}
+
+	got := changedKeys(previous, current)
+	sort.Strings(got)
+
+	want := []string{"b", "c", "d"}
+	if len(got) != len(want) {
+		t.Fatalf("changedKeys = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("changedKeys = %v, want %v", got, want)
+		}
+	}
+}
+
+func drainNames(ch chan event.GenericEvent) []string {
+	var names []string
+	for len(ch) > 0 {
+		names = append(names, (<-ch).Object.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DomainEntry is the value stored under a domain key. A plain string only sets
+// Domain; a JSON object can also carry defaults for requests using the domain.
+type DomainEntry struct {
+	// Domain is the base domain requests build their FQDN from
+	Domain string `json:"domain"`
+	// IssuerName is the default cert-manager issuer
+	IssuerName string `json:"issuerName,omitempty"`
+	// IssuerKind is the default issuer kind (Issuer or ClusterIssuer)
+	IssuerKind string `json:"issuerKind,omitempty"`
+	// Entrypoints are the default Traefik entrypoints
+	Entrypoints []string `json:"entrypoints,omitempty"`
+	// CertResolver is the default Traefik cert resolver
+	CertResolver string `json:"certResolver,omitempty"`
+	// Middlewares are the default Traefik middlewares
+	Middlewares []DomainMiddleware `json:"middlewares,omitempty"`
+	// Values are free-form values requests can reference in templates, such as the certificate subject
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// DomainMiddleware references a Traefik middleware from a DomainEntry
+type DomainMiddleware struct {
+	// Name of the middleware
+	Name string `json:"name"`
+	// Namespace of the middleware, defaults to the request's namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ParseDomainEntry parses a stored domain value: a domain string, a string holding
+// a JSON object, or an already decoded object such as a nested Vault value
+func ParseDomainEntry(raw interface{}) (*DomainEntry, error) {
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if !strings.HasPrefix(v, "{") {
+			if v == "" {
+				return nil, fmt.Errorf("domain is empty")
+			}
+			return &DomainEntry{Domain: v}, nil
+		}
+		data = []byte(v)
+	case map[string]interface{}:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("failed to encode domain entry: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("domain entry must be a string or an object (got type %T)", raw)
+	}
+
+	var entry DomainEntry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entry); err != nil {
+		return nil, fmt.Errorf("failed to parse domain entry: %w", err)
+	}
+
+	if err := entry.validate(); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// validate checks the decoded fields
+func (e *DomainEntry) validate() error {
+	if e.Domain == "" {
+		return fmt.Errorf("domain is empty")
+	}
+	if e.IssuerKind != "" && e.IssuerKind != "Issuer" && e.IssuerKind != "ClusterIssuer" {
+		return fmt.Errorf("issuerKind must be Issuer or ClusterIssuer, got '%s'", e.IssuerKind)
+	}
+	for _, mw := range e.Middlewares {
+		if mw.Name == "" {
+			return fmt.Errorf("middleware name cannot be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Supported domain source kinds
+const (
+	DomainSourceVault     = "Vault"
+	DomainSourceConfigMap = "ConfigMap"
+	DomainSourceSecret    = "Secret"
+	DomainSourceFile      = "File"
+)
+
+// DomainSourceAnnotation set to "true" allows requests to name a Secret as their domain source.
+// Domains read from a Secret end up in request status, so Secrets must opt in.
+const DomainSourceAnnotation = "networking.alm.homelab/domain-source"
+
+// DomainLookup identifies a domain value within a DomainSource
+type DomainLookup struct {
+	// Namespace of the requesting object, used when Path does not name one
+	Namespace string
+	// Path locates the domain data: a Vault path, a ConfigMap or Secret
+	// name ("name" or "namespace/name") or a file path
+	Path string
+	// Key selects the domain within the data at Path
+	Key string
+	// Connection selects the Vault connection for Vault lookups; empty selects the default
+	Connection string
+	// Requested is set when Path was named by the request rather than configured on the operator
+	Requested bool
+}
+
+// DomainSource resolves domain keys to domain entries
+type DomainSource interface {
+	GetDomain(ctx context.Context, lookup DomainLookup) (*DomainEntry, error)
+}
+
+// DomainSources selects a DomainSource by kind
+type DomainSources struct {
+	// DefaultKind is used when a request does not select a source
+	DefaultKind string
+	// DefaultPath locates the domain data for non-Vault sources when a request does not name it
+	DefaultPath string
+	// Sources maps a kind to its implementation
+	Sources map[string]DomainSource
+}
+
+// NewDomainSources returns DomainSources with all built-in sources registered.
+// ConfigMap and Secret sources read through reader; the Vault source gets its clients from vaultClients.
+func NewDomainSources(reader client.Reader, vaultClients VaultClientProvider, defaultKind, defaultPath string) *DomainSources {
+	return &DomainSources{
+		DefaultKind: defaultKind,
+		DefaultPath: defaultPath,
+		Sources: map[string]DomainSource{
+			DomainSourceVault:     VaultDomainSource{Clients: vaultClients},
+			DomainSourceConfigMap: ConfigMapDomainSource{Reader: reader},
+			DomainSourceSecret:    SecretDomainSource{Reader: reader},
+			DomainSourceFile:      FileDomainSource{},
+		},
+	}
+}
+
+// Get returns the DomainSource registered for kind
+func (d *DomainSources) Get(kind string) (DomainSource, error) {
+	source, ok := d.Sources[kind]
+	if !ok || source == nil {
+		return nil, fmt.Errorf("unsupported domain source kind '%s'", kind)
+	}
+	return source, nil
+}
+
+// VaultDomainSource reads domains from a Vault KV secret
+type VaultDomainSource struct {
+	// Clients resolves lookup connections; when nil only the default connection is available
+	Clients VaultClientProvider
+}
+
+// GetDomain implements DomainSource
+func (s VaultDomainSource) GetDomain(ctx context.Context, lookup DomainLookup) (*DomainEntry, error) {
+	return getDomainFromVault(ctx, s.Clients, lookup.Connection, lookup.Path, lookup.Key)
+}
+
+// ConfigMapDomainSource reads domains from the data of a ConfigMap
+type ConfigMapDomainSource struct {
+	Reader client.Reader
+}
+
+// GetDomain implements DomainSource
+func (s ConfigMapDomainSource) GetDomain(ctx context.Context, lookup DomainLookup) (*DomainEntry, error) {
+	if err := validateLookup(lookup); err != nil {
+		return nil, err
+	}
+
+	var cm corev1.ConfigMap
+	key := objectKey(lookup)
+	if err := s.Reader.Get(ctx, key, &cm); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %w", key, err)
+	}
+
+	return domainValue(cm.Data, lookup.Key, "ConfigMap "+key.String())
+}
+
+// SecretDomainSource reads domains from the data of a Secret. A Secret named by a request
+// must carry DomainSourceAnnotation; the operator's configured Secret is always allowed.
+type SecretDomainSource struct {
+	Reader client.Reader
+}
+
+// GetDomain implements DomainSource. Errors never include the Secret's data.
+func (s SecretDomainSource) GetDomain(ctx context.Context, lookup DomainLookup) (*DomainEntry, error) {
+	if err := validateLookup(lookup); err != nil {
+		return nil, err
+	}
+
+	var secret corev1.Secret
+	key := objectKey(lookup)
+	if err := s.Reader.Get(ctx, key, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+	if lookup.Requested && secret.Annotations[DomainSourceAnnotation] != "true" {
+		return nil, fmt.Errorf("domain source Secret %s is not annotated with %s=true", key, DomainSourceAnnotation)
+	}
+
+	val, ok := secret.Data[lookup.Key]
+	if !ok {
+		return nil, fmt.Errorf("domain key '%s' not found in Secret %s", lookup.Key, key)
+	}
+	entry, err := ParseDomainEntry(string(val))
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain key '%s' in Secret %s", lookup.Key, key)
+	}
+
+	return entry, nil
+}
+
+// FileDomainSource reads domains from a local file or directory.
+// A file holds a YAML or JSON map of keys to domains; a directory holds one
+// file per key, as produced by mounting a ConfigMap as a volume.
+type FileDomainSource struct{}
+
+// GetDomain implements DomainSource
+func (FileDomainSource) GetDomain(_ context.Context, lookup DomainLookup) (*DomainEntry, error) {
+	if err := validateLookup(lookup); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(lookup.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat domain file %s: %w", lookup.Path, err)
+	}
+
+	if info.IsDir() {
+		if strings.ContainsRune(lookup.Key, filepath.Separator) {
+			return nil, fmt.Errorf("domain key '%s' is not a valid file name", lookup.Key)
+		}
+		raw, err := os.ReadFile(filepath.Join(lookup.Path, lookup.Key))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("domain key '%s' not found in directory %s", lookup.Key, lookup.Path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read domain key '%s' from directory %s: %w", lookup.Key, lookup.Path, err)
+		}
+		return domainValue(map[string]string{lookup.Key: string(raw)}, lookup.Key, "directory "+lookup.Path)
+	}
+
+	raw, err := os.ReadFile(lookup.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domain file %s: %w", lookup.Path, err)
+	}
+
+	// Values may be nested objects, so structured entries can be written as YAML
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse domain file %s: %w", lookup.Path, err)
+	}
+
+	return domainValue(data, lookup.Key, "file "+lookup.Path)
+}
+
+// StaticDomainSource serves domains from an in-memory map, ignoring the lookup path.
+// Values are parsed like any other stored domain, so they may hold JSON entries.
+type StaticDomainSource map[string]string
+
+// GetDomain implements DomainSource
+func (s StaticDomainSource) GetDomain(_ context.Context, lookup DomainLookup) (*DomainEntry, error) {
+	if lookup.Key == "" {
+		return nil, fmt.Errorf("domain key cannot be empty")
+	}
+	return domainValue(s, lookup.Key, "static domain source")
+}
+
+// validateLookup checks the fields every path-based source requires
+func validateLookup(lookup DomainLookup) error {
+	if lookup.Path == "" {
+		return fmt.Errorf("domain source path cannot be empty")
+	}
+	if lookup.Key == "" {
+		return fmt.Errorf("domain key cannot be empty")
+	}
+	return nil
+}
+
+// objectKey splits a "namespace/name" path, falling back to the lookup namespace
+func objectKey(lookup DomainLookup) client.ObjectKey {
+	if ns, name, ok := strings.Cut(lookup.Path, "/"); ok {
+		return client.ObjectKey{Namespace: ns, Name: name}
+	}
+	return client.ObjectKey{Namespace: lookup.Namespace, Name: lookup.Path}
+}
+
+// domainValue parses the domain entry stored under key
+func domainValue[V any](data map[string]V, key, location string) (*DomainEntry, error) {
+	val, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("domain key '%s' not found in %s", key, location)
+	}
+
+	entry, err := ParseDomainEntry(val)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain key '%s' in %s: %w", key, location, err)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestKubernetesDomainSources validates ConfigMap and Secret lookups
+func TestKubernetesDomainSources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "domains", Namespace: "apps"},
+			Data:       map[string]string{"prodDomain": "example.com", "emptyDomain": ""},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "domains", Namespace: "shared"},
+			Data:       map[string][]byte{"prodDomain": []byte("secret.example.com")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "domains",
+				Namespace:   "apps",
+				Annotations: map[string]string{DomainSourceAnnotation: "true"},
+			},
+			Data: map[string][]byte{"prodDomain": []byte("apps.example.com"), "broken": []byte(`{"password": "hunter2"}`)},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "apps"},
+			Data:       map[string][]byte{"password": []byte("hunter2")},
+		},
+	).Build()
+
+	tests := []struct {
+		name      string
+		source    DomainSource
+		lookup    DomainLookup
+		want      string
+		wantError bool
+	}{
+		{
+			name:   "configmap in request namespace",
+			source: ConfigMapDomainSource{Reader: reader},
+			lookup: DomainLookup{Namespace: "apps", Path: "domains", Key: "prodDomain"},
+			want:   "example.com",
+		},
+		{
+			name:   "secret with namespaced path",
+			source: SecretDomainSource{Reader: reader},
+			lookup: DomainLookup{Namespace: "apps", Path: "shared/domains", Key: "prodDomain"},
+			want:   "secret.example.com",
+		},
+		{
+			name:      "missing key",
+			source:    ConfigMapDomainSource{Reader: reader},
+			lookup:    DomainLookup{Namespace: "apps", Path: "domains", Key: "stagingDomain"},
+			wantError: true,
+		},
+		{
+			name:      "empty value",
+			source:    ConfigMapDomainSource{Reader: reader},
+			lookup:    DomainLookup{Namespace: "apps", Path: "domains", Key: "emptyDomain"},
+			wantError: true,
+		},
+		{
+			name:   "secret named by the request",
+			source: SecretDomainSource{Reader: reader},
+			lookup: DomainLookup{Namespace: "apps", Path: "domains", Key: "prodDomain", Requested: true},
+			want:   "apps.example.com",
+		},
+		{
+			name:      "secret named by the request without opt-in",
+			source:    SecretDomainSource{Reader: reader},
+			lookup:    DomainLookup{Namespace: "apps", Path: "db-credentials", Key: "password", Requested: true},
+			wantError: true,
+		},
+		{
+			name:      "invalid secret value",
+			source:    SecretDomainSource{Reader: reader},
+			lookup:    DomainLookup{Namespace: "apps", Path: "domains", Key: "broken", Requested: true},
+			wantError: true,
+		},
+		{
+			name:      "missing object",
+			source:    SecretDomainSource{Reader: reader},
+			lookup:    DomainLookup{Namespace: "other", Path: "domains", Key: "prodDomain"},
+			wantError: true,
+		},
+		{
+			name:      "empty path",
+			source:    ConfigMapDomainSource{Reader: reader},
+			lookup:    DomainLookup{Namespace: "apps", Key: "prodDomain"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.GetDomain(context.Background(), tt.lookup)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error, got domain %q", got.Domain)
+				} else if strings.Contains(err.Error(), "hunter2") {
+					t.Errorf("error %q leaks the Secret's data", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Domain != tt.want {
+				t.Errorf("GetDomain = %v, want %v", got.Domain, tt.want)
+			}
+		})
+	}
+}
+
+// TestFileDomainSource validates lookups from a domain file and a mounted directory
+func TestFileDomainSource(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "domains.yaml")
+	content := "prodDomain: example.com\nlabDomain:\n  domain: lab.example.com\n  issuerName: lab-ca\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mounted := filepath.Join(dir, "mounted")
+	if err := os.Mkdir(mounted, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mounted, "prodDomain"), []byte("mounted.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := FileDomainSource{}
+
+	got, err := source.GetDomain(context.Background(), DomainLookup{Path: file, Key: "prodDomain"})
+	if err != nil || got.Domain != "example.com" {
+		t.Errorf("file lookup = %v, %v; want example.com", got, err)
+	}
+
+	got, err = source.GetDomain(context.Background(), DomainLookup{Path: file, Key: "labDomain"})
+	if err != nil || got.Domain != "lab.example.com" || got.IssuerName != "lab-ca" {
+		t.Errorf("structured file lookup = %v, %v; want lab.example.com with issuer lab-ca", got, err)
+	}
+
+	got, err = source.GetDomain(context.Background(), DomainLookup{Path: mounted, Key: "prodDomain"})
+	if err != nil || got.Domain != "mounted.example.com" {
+		t.Errorf("directory lookup = %v, %v; want mounted.example.com", got, err)
+	}
+
+	if _, err := source.GetDomain(context.Background(), DomainLookup{Path: mounted, Key: "stagingDomain"}); err == nil {
+		t.Error("Expected error for missing key in directory")
+	}
+}
+
+// TestDomainSourcesGet validates kind selection
+func TestDomainSourcesGet(t *testing.T) {
+	sources := NewDomainSources(nil, nil, DomainSourceVault, "")
+
+	for _, kind := range []string{DomainSourceVault, DomainSourceConfigMap, DomainSourceSecret, DomainSourceFile} {
+		if _, err := sources.Get(kind); err != nil {
+			t.Errorf("Get(%s) returned error: %v", kind, err)
+		}
+	}
+
+	if _, err := sources.Get("Consul"); err == nil {
+		t.Error("Expected error for unsupported kind")
+	}
+}
+
+// TestParseDomainEntry validates plain and structured domain values
+func TestParseDomainEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       interface{}
+		want      DomainEntry
+		wantError bool
+	}{
+		{
+			name: "plain string",
+			raw:  " example.com\n",
+			want: DomainEntry{Domain: "example.com"},
+		},
+		{
+			name: "JSON string",
+			raw:  `{"domain":"example.com","issuerName":"letsencrypt","entrypoints":["websecure"]}`,
+			want: DomainEntry{Domain: "example.com", IssuerName: "letsencrypt", Entrypoints: []string{"websecure"}},
+		},
+		{
+			name: "nested object",
+			raw: map[string]interface{}{
+				"domain":       "example.com",
+				"issuerKind":   "Issuer",
+				"certResolver": "le",
+				"middlewares":  []interface{}{map[string]interface{}{"name": "auth", "namespace": "traefik"}},
+			},
+			want: DomainEntry{
+				Domain:       "example.com",
+				IssuerKind:   "Issuer",
+				CertResolver: "le",
+				Middlewares:  []DomainMiddleware{{Name: "auth", Namespace: "traefik"}},
+			},
+		},
+		{
+			name: "values",
+			raw:  `{"domain": "example.com", "values": {"organization": "Homelab", "country": "RO"}}`,
+			want: DomainEntry{
+				Domain: "example.com",
+				Values: map[string]string{"organization": "Homelab", "country": "RO"},
+			},
+		},
+		{
+			name:      "empty string",
+			raw:       "",
+			wantError: true,
+		},
+		{
+			name:      "object without domain",
+			raw:       `{"issuerName":"letsencrypt"}`,
+			wantError: true,
+		},
+		{
+			name:      "unknown field",
+			raw:       `{"domain":"example.com","issuer":"letsencrypt"}`,
+			wantError: true,
+		},
+		{
+			name:      "invalid issuer kind",
+			raw:       `{"domain":"example.com","issuerKind":"Vault"}`,
+			wantError: true,
+		},
+		{
+			name:      "unsupported type",
+			raw:       42,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDomainEntry(tt.raw)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseDomainEntry = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
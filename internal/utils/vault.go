@@ -1,90 +1,293 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 
 	vault "github.com/hashicorp/vault/api"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var (
-	vaultClient     *vault.Client
-	vaultClientOnce sync.Once
-	vaultClientErr  error
+	vaultClient   *vault.Client
+	vaultClientMu sync.Mutex
 )
 
-// GetVaultClient returns a singleton Vault client instance
+// VaultClientProvider returns the Vault client for a connection.
+// An empty connection selects the client configured from the environment.
+type VaultClientProvider interface {
+	VaultClient(ctx context.Context, connection string) (*vault.Client, error)
+}
+
+// VaultConnectionConfig configures a Vault client that does not come from the environment
+type VaultConnectionConfig struct {
+	// Address of the Vault server
+	Address string
+	// CABundle is a PEM encoded CA bundle for the server certificate
+	CABundle string
+	// Namespace is the Vault Enterprise or OpenBao namespace
+	Namespace string
+	// Token is used when Auth.Method is token
+	Token string
+	// Auth selects how the client logs in
+	Auth VaultAuthConfig
+}
+
+// GetVaultClient returns a singleton Vault client instance.
+// When VAULT_AUTH_METHOD selects a login method the client logs in on first use
+// and keeps its token renewed in the background. Failed attempts are not cached,
+// so a later call retries.
 func GetVaultClient() (*vault.Client, error) {
-	vaultClientOnce.Do(func() {
-		config := vault.DefaultConfig()
-		if config.Error != nil {
-			vaultClientErr = fmt.Errorf("failed to create Vault config: %w", config.Error)
-			return
-		}
+	vaultClientMu.Lock()
+	defer vaultClientMu.Unlock()
 
-		client, err := vault.NewClient(config)
+	if vaultClient != nil {
+		return vaultClient, nil
+	}
+
+	config := vault.DefaultConfig()
+	if config.Error != nil {
+		return nil, fmt.Errorf("failed to create Vault config: %w", config.Error)
+	}
+
+	client, err := vault.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+
+	auth := VaultAuthConfigFromEnv()
+	if auth.Method != VaultAuthToken {
+		ctx, cancel := context.WithTimeout(context.Background(), vaultLoginTimeout)
+		defer cancel()
+
+		secret, err := auth.login(ctx, client)
 		if err != nil {
-			vaultClientErr = fmt.Errorf("failed to create Vault client: %w", err)
-			return
+			return nil, err
 		}
+		go auth.keepTokenAlive(context.Background(), client, secret)
+	}
 
-		vaultClient = client
-	})
+	vaultClient = client
+	return vaultClient, nil
+}
+
+// NewVaultClient creates a client for cfg, ignoring the VAULT_* environment, and logs in.
+// Tokens obtained by logging in are renewed until ctx is done.
+func NewVaultClient(ctx context.Context, cfg VaultConnectionConfig) (*vault.Client, error) {
+	config := vault.DefaultConfig()
+	if config.Error != nil {
+		return nil, fmt.Errorf("failed to create Vault config: %w", config.Error)
+	}
+
+	config.Address = cfg.Address
+	if cfg.CABundle != "" {
+		if err := config.ConfigureTLS(&vault.TLSConfig{CACertBytes: []byte(cfg.CABundle)}); err != nil {
+			return nil, fmt.Errorf("failed to configure Vault CA bundle: %w", err)
+		}
+	}
 
-	return vaultClient, vaultClientErr
+	client, err := vault.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+
+	// NewClient picks up VAULT_TOKEN and VAULT_NAMESPACE, which belong to the default connection
+	client.ClearToken()
+	client.ClearNamespace()
+	if cfg.Namespace != "" {
+		client.SetNamespace(cfg.Namespace)
+	}
+
+	if cfg.Auth.Method == VaultAuthToken {
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("token auth requires a token")
+		}
+		client.SetToken(cfg.Token)
+		return client, nil
+	}
+
+	loginCtx, cancel := context.WithTimeout(ctx, vaultLoginTimeout)
+	defer cancel()
+
+	secret, err := cfg.Auth.login(loginCtx, client)
+	if err != nil {
+		return nil, err
+	}
+	go cfg.Auth.keepTokenAlive(ctx, client, secret)
+
+	return client, nil
 }
 
-// GetDomainFromVault fetches a domain value from Vault at the specified path and key
-func GetDomainFromVault(path, key string) (string, error) {
+// GetDomainFromVault fetches the domain entry from Vault at the specified path and key.
+// Reads go through the shared Vault cache.
+func GetDomainFromVault(ctx context.Context, path, key string) (*DomainEntry, error) {
+	return getDomainFromVault(ctx, nil, "", path, key)
+}
+
+// getDomainFromVault reads key from the secret at path on the given connection
+// through the shared Vault cache. A nil provider only serves the default connection.
+func getDomainFromVault(ctx context.Context, clients VaultClientProvider, connection, path, key string) (*DomainEntry, error) {
 	if path == "" {
-		return "", fmt.Errorf("vault path cannot be empty")
+		return nil, fmt.Errorf("vault path cannot be empty")
 	}
 	if key == "" {
-		return "", fmt.Errorf("domain key cannot be empty")
+		return nil, fmt.Errorf("domain key cannot be empty")
 	}
 
-	client, err := GetVaultClient()
+	secret, err := vaultCache.Read(ctx, connection, path, func(ctx context.Context) (*VaultKVSecret, error) {
+		client, err := VaultClientFor(ctx, clients, connection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Vault client: %w", err)
+		}
+		return ReadVaultKV(ctx, client, path)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to get Vault client: %w", err)
+		return nil, err
 	}
 
-	secret, err := client.Logical().Read(path)
+	return domainValue(secret.Data, key, "secret at path "+path)
+}
+
+// VaultClientFor returns the client for connection from clients, falling back to the
+// environment-configured client for the default connection when clients is nil
+func VaultClientFor(ctx context.Context, clients VaultClientProvider, connection string) (*vault.Client, error) {
+	if clients != nil {
+		return clients.VaultClient(ctx, connection)
+	}
+	if connection != "" {
+		return nil, fmt.Errorf("vault connection %s requested but no connections are configured", connection)
+	}
+	return GetVaultClient()
+}
+
+// VaultKVSecret holds the data and version of a KV secret.
+// Version is zero for KV v1 secrets, which are not versioned.
+type VaultKVSecret struct {
+	Data    map[string]interface{}
+	Version int64
+}
+
+// kvMount describes the KV secrets engine a path belongs to
+type kvMount struct {
+	// Path of the mount, with a trailing slash
+	Path string
+	// Version of the KV engine, 1 or 2
+	Version int
+}
+
+// ReadVaultKV reads the KV secret at path with client, bypassing the cache.
+// The KV version is detected from the mount, so both KV v1 paths and logical
+// KV v2 paths such as kv/domains work; kv/data/domains is accepted as well.
+func ReadVaultKV(ctx context.Context, client *vault.Client, path string) (*VaultKVSecret, error) {
+	mount, err := lookupKVMount(ctx, client, path)
+	if err != nil {
+		// Tokens that cannot query the mount fall back to reading the path as written
+		log.FromContext(ctx).V(1).Info("Could not detect KV mount version", "path", path, "error", err.Error())
+	}
+
+	readPath := kvReadPath(mount, path)
+	secret, err := client.Logical().ReadWithContext(ctx, readPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read secret from Vault at path %s: %w", path, err)
+		return nil, fmt.Errorf("failed to read secret from Vault at path %s: %w", readPath, err)
+	}
+
+	version := 0
+	if mount != nil {
+		version = mount.Version
+	}
+
+	return parseKVSecret(readPath, secret, version)
+}
+
+// lookupKVMount asks Vault which mount path belongs to and which KV version it runs
+func lookupKVMount(ctx context.Context, client *vault.Client, path string) (*kvMount, error) {
+	secret, err := client.Logical().ReadWithContext(ctx, "sys/internal/ui/mounts/"+strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no mount information for path %s", path)
+	}
+
+	mountPath, _ := secret.Data["path"].(string)
+	if mountPath == "" {
+		return nil, fmt.Errorf("no mount information for path %s", path)
 	}
 
+	mount := &kvMount{Path: mountPath, Version: 1}
+	if options, ok := secret.Data["options"].(map[string]interface{}); ok {
+		if version, _ := options["version"].(string); version == "2" {
+			mount.Version = 2
+		}
+	}
+
+	return mount, nil
+}
+
+// kvReadPath returns the API path to read: KV v2 paths get the data/ segment
+// after the mount unless it is already there
+func kvReadPath(mount *kvMount, path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if mount == nil || mount.Version != 2 || !strings.HasPrefix(path, mount.Path) {
+		return path
+	}
+
+	rest := strings.TrimPrefix(path, mount.Path)
+	if strings.HasPrefix(rest, "data/") {
+		return path
+	}
+	return mount.Path + "data/" + rest
+}
+
+// parseKVSecret extracts the data and version from a KV read response.
+// When version is unknown (zero) it is inferred from the shape of the response.
+func parseKVSecret(path string, secret *vault.Secret, version int) (*VaultKVSecret, error) {
 	if secret == nil {
-		return "", fmt.Errorf("no secret found at Vault path %s", path)
+		return nil, fmt.Errorf("no secret found at Vault path %s", path)
 	}
 
 	if secret.Data == nil {
-		return "", fmt.Errorf("secret at path %s has no data", path)
+		return nil, fmt.Errorf("secret at path %s has no data", path)
 	}
 
-	// For KV v2, data is nested under "data" key
-	dataRaw, ok := secret.Data["data"]
-	if !ok {
-		return "", fmt.Errorf("secret at path %s is missing 'data' field (ensure you're using KV v2 path format)", path)
+	if version == 0 {
+		version = 1
+		_, hasData := secret.Data["data"].(map[string]interface{})
+		_, hasMetadata := secret.Data["metadata"].(map[string]interface{})
+		if hasData && hasMetadata {
+			version = 2
+		}
 	}
 
-	data, ok := dataRaw.(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("secret data at path %s is not a map", path)
+	if version == 1 {
+		return &VaultKVSecret{Data: secret.Data}, nil
 	}
 
-	valRaw, ok := data[key]
+	// For KV v2, data is nested under "data" key
+	dataRaw, ok := secret.Data["data"]
 	if !ok {
-		return "", fmt.Errorf("domain key '%s' not found in secret at path %s", key, path)
+		return nil, fmt.Errorf("secret at path %s is missing 'data' field", path)
 	}
 
-	val, ok := valRaw.(string)
+	data, ok := dataRaw.(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("domain key '%s' at path %s is not a string (got type %T)", key, path, valRaw)
+		return nil, fmt.Errorf("secret data at path %s is not a map", path)
 	}
 
-	if val == "" {
-		return "", fmt.Errorf("domain key '%s' at path %s is empty", key, path)
+	kv := &VaultKVSecret{Data: data}
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		switch v := metadata["version"].(type) {
+		case json.Number:
+			kv.Version, _ = v.Int64()
+		case float64:
+			kv.Version = int64(v)
+		case int:
+			kv.Version = int64(v)
+		}
 	}
 
-	return val, nil
+	return kv, nil
 }
@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Supported Vault auth methods, selected with VAULT_AUTH_METHOD
+const (
+	VaultAuthToken      = "token"
+	VaultAuthKubernetes = "kubernetes"
+	VaultAuthAppRole    = "approle"
+)
+
+const (
+	defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	vaultLoginTimeout              = 30 * time.Second
+	minLoginBackoff                = time.Second
+	maxLoginBackoff                = 2 * time.Minute
+)
+
+// VaultAuthConfig describes how the operator logs in to Vault
+type VaultAuthConfig struct {
+	// Method is one of token, kubernetes or approle
+	Method string
+	// MountPath of the auth method, defaults to the method name
+	MountPath string
+	// Role is the Kubernetes auth role
+	Role string
+	// TokenPath is the service account JWT presented to the Kubernetes auth method
+	TokenPath string
+	// RoleID is the AppRole role ID
+	RoleID string
+	// SecretID is the AppRole secret ID
+	SecretID string
+	// SecretIDPath is a file holding the AppRole secret ID, read on every login
+	SecretIDPath string
+}
+
+// VaultAuthConfigFromEnv reads the auth configuration from VAULT_* environment variables
+func VaultAuthConfigFromEnv() VaultAuthConfig {
+	cfg := VaultAuthConfig{
+		Method:       strings.ToLower(os.Getenv("VAULT_AUTH_METHOD")),
+		MountPath:    os.Getenv("VAULT_AUTH_MOUNT"),
+		Role:         os.Getenv("VAULT_K8S_ROLE"),
+		TokenPath:    os.Getenv("VAULT_K8S_TOKEN_PATH"),
+		RoleID:       os.Getenv("VAULT_APPROLE_ROLE_ID"),
+		SecretID:     os.Getenv("VAULT_APPROLE_SECRET_ID"),
+		SecretIDPath: os.Getenv("VAULT_APPROLE_SECRET_ID_PATH"),
+	}
+
+	if cfg.Method == "" {
+		cfg.Method = VaultAuthToken
+	}
+	if cfg.MountPath == "" {
+		cfg.MountPath = cfg.Method
+	}
+	if cfg.TokenPath == "" {
+		cfg.TokenPath = defaultServiceAccountTokenPath
+	}
+
+	return cfg
+}
+
+// login authenticates client with the configured method and sets its token
+func (c VaultAuthConfig) login(ctx context.Context, client *vault.Client) (*vault.Secret, error) {
+	var data map[string]interface{}
+
+	switch c.Method {
+	case VaultAuthKubernetes:
+		if c.Role == "" {
+			return nil, fmt.Errorf("VAULT_K8S_ROLE is required for Kubernetes auth")
+		}
+		jwt, err := os.ReadFile(c.TokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account token from %s: %w", c.TokenPath, err)
+		}
+		data = map[string]interface{}{
+			"role": c.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+	case VaultAuthAppRole:
+		if c.RoleID == "" {
+			return nil, fmt.Errorf("VAULT_APPROLE_ROLE_ID is required for AppRole auth")
+		}
+		secretID := c.SecretID
+		if c.SecretIDPath != "" {
+			raw, err := os.ReadFile(c.SecretIDPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read AppRole secret ID from %s: %w", c.SecretIDPath, err)
+			}
+			secretID = strings.TrimSpace(string(raw))
+		}
+		data = map[string]interface{}{
+			"role_id":   c.RoleID,
+			"secret_id": secretID,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Vault auth method '%s'", c.Method)
+	}
+
+	path := fmt.Sprintf("auth/%s/login", strings.Trim(c.MountPath, "/"))
+	secret, err := client.Logical().WriteWithContext(ctx, path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in to Vault at %s: %w", path, err)
+	}
+
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("vault login at %s returned no token", path)
+	}
+
+	client.SetToken(secret.Auth.ClientToken)
+	return secret, nil
+}
+
+// keepTokenAlive renews the token in secret for as long as Vault allows and logs
+// in again whenever the lease ends or renewal fails. It returns when ctx is done.
+func (c VaultAuthConfig) keepTokenAlive(ctx context.Context, client *vault.Client, secret *vault.Secret) {
+	logger := log.Log.WithName("vault").WithValues("address", client.Address())
+	backoff := minLoginBackoff
+
+	for ctx.Err() == nil {
+		if secret != nil {
+			if err := watchTokenLifetime(ctx, client, secret); err != nil {
+				logger.Error(err, "Vault token renewal failed, logging in again")
+			} else {
+				logger.Info("Vault token lease ended, logging in again")
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		loginCtx, cancel := context.WithTimeout(ctx, vaultLoginTimeout)
+		var err error
+		secret, err = c.login(loginCtx, client)
+		cancel()
+
+		if err != nil {
+			logger.Error(err, "failed to log in to Vault", "retryIn", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxLoginBackoff)
+			continue
+		}
+
+		backoff = minLoginBackoff
+		logger.Info("Logged in to Vault", "method", c.Method, "ttl", secret.Auth.LeaseDuration)
+	}
+}
+
+// watchTokenLifetime blocks until the token in secret can no longer be renewed or ctx is done
+func watchTokenLifetime(ctx context.Context, client *vault.Client, secret *vault.Secret) error {
+	watcher, err := client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{Secret: secret})
+	if err != nil {
+		return fmt.Errorf("failed to create Vault token watcher: %w", err)
+	}
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.DoneCh():
+			return err
+		case <-watcher.RenewCh():
+			log.Log.WithName("vault").V(1).Info("Renewed Vault token")
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultVaultCacheTTL is how long a Vault read is served from the cache
+	DefaultVaultCacheTTL = 30 * time.Second
+
+	// vaultReadTimeout bounds reads made on behalf of callers without a deadline
+	vaultReadTimeout = 30 * time.Second
+)
+
+var (
+	vaultCacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "homelab_alm_vault_cache_hits_total",
+		Help: "Number of Vault reads served from the cache",
+	}, []string{"connection", "path"})
+
+	vaultCacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "homelab_alm_vault_cache_misses_total",
+		Help: "Number of Vault reads that were not in the cache",
+	}, []string{"connection", "path"})
+
+	// vaultCache is shared by every Vault domain lookup
+	vaultCache = NewVaultCache(DefaultVaultCacheTTL)
+)
+
+func init() {
+	metrics.Registry.MustRegister(vaultCacheHits, vaultCacheMisses)
+}
+
+// VaultCache caches KV reads by connection and path and deduplicates concurrent
+// reads of the same secret. Returned secrets are shared between callers and must not be modified.
+type VaultCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]vaultCacheEntry
+	group   singleflight.Group
+}
+
+type vaultCacheEntry struct {
+	secret  *VaultKVSecret
+	expires time.Time
+}
+
+// NewVaultCache creates a VaultCache serving reads for ttl.
+// A zero ttl disables caching but still deduplicates concurrent reads.
+func NewVaultCache(ttl time.Duration) *VaultCache {
+	return &VaultCache{
+		ttl:     ttl,
+		entries: map[string]vaultCacheEntry{},
+	}
+}
+
+// Read returns the secret at path on connection from the cache, calling read on a miss.
+// It returns early when ctx is done, leaving the shared read to finish for other callers.
+func (c *VaultCache) Read(ctx context.Context, connection, path string,
+	read func(ctx context.Context) (*VaultKVSecret, error)) (*VaultKVSecret, error) {
+	key := vaultCacheKey(connection, path)
+	if secret, ok := c.get(key); ok {
+		vaultCacheHits.WithLabelValues(connection, path).Inc()
+		return secret, nil
+	}
+	vaultCacheMisses.WithLabelValues(connection, path).Inc()
+
+	result := c.group.DoChan(key, func() (interface{}, error) {
+		readCtx, cancel := sharedReadContext(ctx)
+		defer cancel()
+
+		secret, err := read(readCtx)
+		if err != nil {
+			return nil, err
+		}
+		c.put(key, secret)
+		return secret, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to read secret from Vault at path %s: %w", path, ctx.Err())
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*VaultKVSecret), nil
+	}
+}
+
+// Invalidate drops the cached secret at path on connection so the next read goes to Vault
+func (c *VaultCache) Invalidate(connection, path string) {
+	key := vaultCacheKey(connection, path)
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+	c.group.Forget(key)
+}
+
+// SetTTL changes how long reads are cached
+func (c *VaultCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	c.entries = map[string]vaultCacheEntry{}
+}
+
+func (c *VaultCache) get(key string) (*VaultKVSecret, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.secret, true
+}
+
+func (c *VaultCache) put(key string, secret *VaultKVSecret) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+	c.entries[key] = vaultCacheEntry{secret: secret, expires: time.Now().Add(c.ttl)}
+}
+
+// vaultCacheKey identifies a secret across connections
+func vaultCacheKey(connection, path string) string {
+	return connection + "#" + path
+}
+
+// sharedReadContext keeps the caller's deadline but not its cancellation, so one
+// caller giving up does not fail the read for everyone waiting on it
+func sharedReadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), vaultReadTimeout)
+}
+
+// SetVaultCacheTTL changes how long Vault domain lookups are cached
+func SetVaultCacheTTL(ttl time.Duration) {
+	vaultCache.SetTTL(ttl)
+}
+
+// InvalidateVaultCache drops the cached secret at path on connection
+func InvalidateVaultCache(connection, path string) {
+	vaultCache.Invalidate(connection, path)
+}
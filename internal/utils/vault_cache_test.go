@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestVaultCacheTTL validates that reads are served from the cache until invalidated
+func TestVaultCacheTTL(t *testing.T) {
+	var calls atomic.Int32
+	cache := NewVaultCache(time.Minute)
+	read := func(_ context.Context) (*VaultKVSecret, error) {
+		calls.Add(1)
+		return &VaultKVSecret{Data: map[string]interface{}{"prodDomain": "example.com"}, Version: 1}, nil
+	}
+
+	for range 3 {
+		if _, err := cache.Read(context.Background(), "", "kv/data/domains", read); err != nil {
+			t.Fatalf("Read returned error: %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Vault reads = %d, want 1", calls.Load())
+	}
+
+	cache.Invalidate("", "kv/data/domains")
+	if _, err := cache.Read(context.Background(), "", "kv/data/domains", read); err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Vault reads after invalidate = %d, want 2", calls.Load())
+	}
+}
+
+// TestVaultCacheErrorsNotCached validates that failed reads are retried
+func TestVaultCacheErrorsNotCached(t *testing.T) {
+	var calls atomic.Int32
+	cache := NewVaultCache(time.Minute)
+	read := func(_ context.Context) (*VaultKVSecret, error) {
+		calls.Add(1)
+		return nil, errors.New("vault sealed")
+	}
+
+	for range 2 {
+		if _, err := cache.Read(context.Background(), "", "kv/data/domains", read); err == nil {
+			t.Fatal("Expected error from failing read")
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Vault reads = %d, want 2", calls.Load())
+	}
+}
+
+// TestVaultCacheDeduplicates validates that concurrent reads share one Vault call
+func TestVaultCacheDeduplicates(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache := NewVaultCache(0)
+	read := func(_ context.Context) (*VaultKVSecret, error) {
+		calls.Add(1)
+		<-release
+		return &VaultKVSecret{Version: 1}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := cache.Read(context.Background(), "", "kv/data/domains", read); err != nil {
+				t.Errorf("Read returned error: %v", err)
+			}
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Vault reads = %d, want 1", calls.Load())
+	}
+}
+
+// TestVaultCacheHonorsContext validates that callers stop waiting when their context ends
+func TestVaultCacheHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	cache := NewVaultCache(time.Minute)
+	read := func(_ context.Context) (*VaultKVSecret, error) {
+		<-release
+		return &VaultKVSecret{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := cache.Read(ctx, "", "kv/data/domains", read); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Read error = %v, want deadline exceeded", err)
+	}
+}
+
+// TestVaultCacheSeparatesConnections validates that the same path on different connections is cached separately
+func TestVaultCacheSeparatesConnections(t *testing.T) {
+	cache := NewVaultCache(time.Minute)
+	readDomain := func(domain string) func(context.Context) (*VaultKVSecret, error) {
+		return func(_ context.Context) (*VaultKVSecret, error) {
+			return &VaultKVSecret{Data: map[string]interface{}{"prodDomain": domain}}, nil
+		}
+	}
+
+	if _, err := cache.Read(context.Background(), "", "kv/data/domains", readDomain("example.com")); err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	secret, err := cache.Read(context.Background(), "ClusterVaultConnection/lab", "kv/data/domains", readDomain("lab.example.com"))
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if got := secret.Data["prodDomain"]; got != "lab.example.com" {
+		t.Errorf("prodDomain = %v, want lab.example.com", got)
+	}
+}
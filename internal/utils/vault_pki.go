@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// VaultPKIIssueRequest holds the parameters of a Vault PKI issue call
+type VaultPKIIssueRequest struct {
+	// CommonName of the certificate
+	CommonName string
+	// AltNames are additional DNS subject alternative names
+	AltNames []string
+	// TTL of the certificate; the role's default when zero
+	TTL time.Duration
+	// PrivateKeyFormat is "pkcs8" to encode the key as PKCS#8, or empty for the key type's default
+	PrivateKeyFormat string
+}
+
+// VaultPKICertificate is a certificate issued by a Vault PKI secrets engine, PEM encoded
+type VaultPKICertificate struct {
+	Certificate string
+	// CAChain is the issuing CA followed by its parents
+	CAChain    []string
+	PrivateKey string
+}
+
+// IssueVaultPKI issues a certificate from the PKI secrets engine at mount using role
+func IssueVaultPKI(ctx context.Context, client *vault.Client, mount, role string,
+	req VaultPKIIssueRequest) (*VaultPKICertificate, error) {
+	data := map[string]interface{}{
+		"common_name": req.CommonName,
+		"format":      "pem",
+	}
+	if len(req.AltNames) > 0 {
+		data["alt_names"] = strings.Join(req.AltNames, ",")
+	}
+	if req.TTL > 0 {
+		data["ttl"] = req.TTL.String()
+	}
+	if req.PrivateKeyFormat != "" {
+		data["private_key_format"] = req.PrivateKeyFormat
+	}
+
+	path := strings.Trim(mount, "/") + "/issue/" + role
+	secret, err := client.Logical().WriteWithContext(ctx, path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate from Vault at path %s: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no certificate returned from Vault at path %s", path)
+	}
+
+	issued := &VaultPKICertificate{}
+	issued.Certificate, _ = secret.Data["certificate"].(string)
+	issued.PrivateKey, _ = secret.Data["private_key"].(string)
+	if chain, ok := secret.Data["ca_chain"].([]interface{}); ok {
+		for _, cert := range chain {
+			if pem, ok := cert.(string); ok {
+				issued.CAChain = append(issued.CAChain, pem)
+			}
+		}
+	}
+	if len(issued.CAChain) == 0 {
+		if ca, ok := secret.Data["issuing_ca"].(string); ok {
+			issued.CAChain = []string{ca}
+		}
+	}
+	if issued.Certificate == "" || issued.PrivateKey == "" {
+		return nil, fmt.Errorf("incomplete certificate returned from Vault at path %s", path)
+	}
+
+	return issued, nil
+}
+
+// ReadVaultPKICAChain returns the PEM encoded CA chain of the PKI secrets engine at mount
+func ReadVaultPKICAChain(ctx context.Context, client *vault.Client, mount string) (string, error) {
+	path := strings.Trim(mount, "/") + "/cert/ca_chain"
+	secret, err := client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read CA chain from Vault at path %s: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("no CA chain found in Vault at path %s", path)
+	}
+
+	chain, _ := secret.Data["certificate"].(string)
+	if chain == "" {
+		return "", fmt.Errorf("no CA chain found in Vault at path %s", path)
+	}
+	return chain, nil
+}
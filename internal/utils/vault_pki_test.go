@@ -0,0 +1,211 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// newTestVaultClient returns a client for the Vault server at address
+func newTestVaultClient(t *testing.T, address, token string) *vault.Client {
+	t.Helper()
+	config := vault.DefaultConfig()
+	config.Address = address
+	client, err := vault.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken(token)
+	return client
+}
+
+// TestIssueVaultPKI validates the issue request parameters and the parsing of its response
+func TestIssueVaultPKI(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       VaultPKIIssueRequest
+		response  string
+		wantBody  map[string]interface{}
+		wantChain []string
+		wantErr   bool
+	}{
+		{
+			name: "all parameters",
+			req: VaultPKIIssueRequest{
+				CommonName:       "app.example.com",
+				AltNames:         []string{"www.example.com", "api.example.com"},
+				TTL:              24 * time.Hour,
+				PrivateKeyFormat: "pkcs8",
+			},
+			response: `{"data":{"certificate":"leaf","private_key":"key","ca_chain":["intermediate","root"],"issuing_ca":"intermediate"}}`,
+			wantBody: map[string]interface{}{
+				"common_name":        "app.example.com",
+				"format":             "pem",
+				"alt_names":          "www.example.com,api.example.com",
+				"ttl":                "24h0m0s",
+				"private_key_format": "pkcs8",
+			},
+			wantChain: []string{"intermediate", "root"},
+		},
+		{
+			name:      "issuing CA without chain",
+			req:       VaultPKIIssueRequest{CommonName: "app.example.com"},
+			response:  `{"data":{"certificate":"leaf","private_key":"key","issuing_ca":"root"}}`,
+			wantBody:  map[string]interface{}{"common_name": "app.example.com", "format": "pem"},
+			wantChain: []string{"root"},
+		},
+		{
+			name:     "missing private key",
+			req:      VaultPKIIssueRequest{CommonName: "app.example.com"},
+			response: `{"data":{"certificate":"leaf"}}`,
+			wantBody: map[string]interface{}{"common_name": "app.example.com", "format": "pem"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/pki/issue/web" {
+					t.Errorf("issue path = %v", r.URL.Path)
+				}
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("invalid request body: %v", err)
+				}
+				if !reflect.DeepEqual(body, tt.wantBody) {
+					t.Errorf("request body = %v, want %v", body, tt.wantBody)
+				}
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			issued, err := IssueVaultPKI(context.Background(), newTestVaultClient(t, server.URL, "token"), "/pki/", "web", tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IssueVaultPKI error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if issued.Certificate != "leaf" || issued.PrivateKey != "key" || !reflect.DeepEqual(issued.CAChain, tt.wantChain) {
+				t.Errorf("issued = %+v, want chain %v", issued, tt.wantChain)
+			}
+		})
+	}
+}
+
+// TestReadVaultPKICAChain validates reading a mount's CA chain
+func TestReadVaultPKICAChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/pki/cert/ca_chain":
+			_, _ = w.Write([]byte(`{"data":{"certificate":"root"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := newTestVaultClient(t, server.URL, "token")
+
+	chain, err := ReadVaultPKICAChain(context.Background(), client, "pki")
+	if err != nil || chain != "root" {
+		t.Errorf("ReadVaultPKICAChain = %q, %v, want root", chain, err)
+	}
+	if _, err := ReadVaultPKICAChain(context.Background(), client, "missing"); err == nil {
+		t.Error("expected an error for a missing mount")
+	}
+}
+
+// TestVaultPKIDevServer issues a certificate from a Vault dev server.
+// It runs when VAULT_DEV_ADDR and VAULT_DEV_TOKEN are set, e.g. for `vault server -dev -dev-root-token-id=root`.
+func TestVaultPKIDevServer(t *testing.T) {
+	address, token := os.Getenv("VAULT_DEV_ADDR"), os.Getenv("VAULT_DEV_TOKEN")
+	if address == "" || token == "" {
+		t.Skip("VAULT_DEV_ADDR and VAULT_DEV_TOKEN not set")
+	}
+
+	ctx := context.Background()
+	client := newTestVaultClient(t, address, token)
+	mount := fmt.Sprintf("pki-test-%d", time.Now().UnixNano())
+	if err := client.Sys().MountWithContext(ctx, mount, &vault.MountInput{
+		Type:   "pki",
+		Config: vault.MountConfigInput{MaxLeaseTTL: "87600h"},
+	}); err != nil {
+		t.Fatalf("failed to enable PKI mount: %v", err)
+	}
+	defer func() { _ = client.Sys().UnmountWithContext(ctx, mount) }()
+
+	if _, err := client.Logical().WriteWithContext(ctx, mount+"/root/generate/internal", map[string]interface{}{
+		"common_name": "homelab-alm test root",
+		"ttl":         "87600h",
+	}); err != nil {
+		t.Fatalf("failed to generate root CA: %v", err)
+	}
+	if _, err := client.Logical().WriteWithContext(ctx, mount+"/roles/web", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"max_ttl":          "72h",
+	}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	issued, err := IssueVaultPKI(ctx, client, mount, "web", VaultPKIIssueRequest{
+		CommonName: "app.example.com",
+		AltNames:   []string{"www.example.com"},
+		TTL:        24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("IssueVaultPKI returned error: %v", err)
+	}
+	chain, err := ReadVaultPKICAChain(ctx, client, mount)
+	if err != nil {
+		t.Fatalf("ReadVaultPKICAChain returned error: %v", err)
+	}
+
+	block, _ := pem.Decode([]byte(issued.Certificate))
+	if block == nil {
+		t.Fatal("issued certificate is not PEM encoded")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(chain)) {
+		t.Fatal("CA chain is not PEM encoded")
+	}
+	for _, name := range []string{"app.example.com", "www.example.com"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: name, Roots: roots}); err != nil {
+			t.Errorf("certificate does not verify for %s: %v", name, err)
+		}
+	}
+	if lifetime := leaf.NotAfter.Sub(leaf.NotBefore); lifetime < 23*time.Hour || lifetime > 25*time.Hour {
+		t.Errorf("lifetime = %v, want about 24h", lifetime)
+	}
+}
@@ -17,6 +17,12 @@ limitations under the License.
 package utils
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	vault "github.com/hashicorp/vault/api"
@@ -109,7 +115,7 @@ func TestGetDomainFromVaultErrorHandling(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := GetDomainFromVault(tt.path, tt.key)
+			_, err := GetDomainFromVault(context.Background(), tt.path, tt.key)
 
 			if tt.wantError && err == nil {
 				t.Error("Expected error for invalid input, got nil")
@@ -127,3 +133,236 @@ func TestGetDomainFromVaultErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+// TestVaultAuthConfigFromEnv validates auth defaults
+func TestVaultAuthConfigFromEnv(t *testing.T) {
+	t.Setenv("VAULT_AUTH_METHOD", "")
+	t.Setenv("VAULT_AUTH_MOUNT", "")
+	t.Setenv("VAULT_K8S_TOKEN_PATH", "")
+
+	cfg := VaultAuthConfigFromEnv()
+	if cfg.Method != VaultAuthToken {
+		t.Errorf("Method = %v, want %v", cfg.Method, VaultAuthToken)
+	}
+
+	t.Setenv("VAULT_AUTH_METHOD", "Kubernetes")
+	cfg = VaultAuthConfigFromEnv()
+	if cfg.Method != VaultAuthKubernetes {
+		t.Errorf("Method = %v, want %v", cfg.Method, VaultAuthKubernetes)
+	}
+	if cfg.MountPath != VaultAuthKubernetes {
+		t.Errorf("MountPath = %v, want %v", cfg.MountPath, VaultAuthKubernetes)
+	}
+	if cfg.TokenPath != defaultServiceAccountTokenPath {
+		t.Errorf("TokenPath = %v, want %v", cfg.TokenPath, defaultServiceAccountTokenPath)
+	}
+}
+
+// TestVaultLogin validates the login requests sent for each auth method
+func TestVaultLogin(t *testing.T) {
+	jwtPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwtPath, []byte("service-account-jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      VaultAuthConfig
+		wantPath string
+		wantBody map[string]string
+	}{
+		{
+			name:     "kubernetes",
+			cfg:      VaultAuthConfig{Method: VaultAuthKubernetes, MountPath: "k8s-homelab", Role: "homelab-alm", TokenPath: jwtPath},
+			wantPath: "/v1/auth/k8s-homelab/login",
+			wantBody: map[string]string{"role": "homelab-alm", "jwt": "service-account-jwt"},
+		},
+		{
+			name:     "approle",
+			cfg:      VaultAuthConfig{Method: VaultAuthAppRole, MountPath: VaultAuthAppRole, RoleID: "role", SecretID: "secret"},
+			wantPath: "/v1/auth/approle/login",
+			wantBody: map[string]string{"role_id": "role", "secret_id": "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("login path = %v, want %v", r.URL.Path, tt.wantPath)
+				}
+				var body map[string]string
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				for k, v := range tt.wantBody {
+					if body[k] != v {
+						t.Errorf("login body[%s] = %v, want %v", k, body[k], v)
+					}
+				}
+				_, _ = w.Write([]byte(`{"auth":{"client_token":"s.test","renewable":true,"lease_duration":3600}}`))
+			}))
+			defer server.Close()
+
+			config := vault.DefaultConfig()
+			config.Address = server.URL
+			client, err := vault.NewClient(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			secret, err := tt.cfg.login(context.Background(), client)
+			if err != nil {
+				t.Fatalf("login returned error: %v", err)
+			}
+			if secret.Auth.LeaseDuration != 3600 {
+				t.Errorf("LeaseDuration = %v, want 3600", secret.Auth.LeaseDuration)
+			}
+			if client.Token() != "s.test" {
+				t.Errorf("client token = %v, want s.test", client.Token())
+			}
+		})
+	}
+
+	if _, err := (VaultAuthConfig{Method: VaultAuthKubernetes}).login(context.Background(), nil); err == nil {
+		t.Error("Expected error for Kubernetes auth without a role")
+	}
+}
+
+// TestKVReadPath validates data/ insertion for KV v2 mounts
+func TestKVReadPath(t *testing.T) {
+	v1 := &kvMount{Path: "secret/", Version: 1}
+	v2 := &kvMount{Path: "kv/", Version: 2}
+
+	tests := []struct {
+		name  string
+		mount *kvMount
+		path  string
+		want  string
+	}{
+		{name: "logical v2 path", mount: v2, path: "kv/domains", want: "kv/data/domains"},
+		{name: "explicit v2 path", mount: v2, path: "kv/data/domains", want: "kv/data/domains"},
+		{name: "nested v2 path", mount: v2, path: "kv/homelab/domains", want: "kv/data/homelab/domains"},
+		{name: "v1 path", mount: v1, path: "secret/domains", want: "secret/domains"},
+		{name: "unknown mount", mount: nil, path: "kv/data/domains", want: "kv/data/domains"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kvReadPath(tt.mount, tt.path); got != tt.want {
+				t.Errorf("kvReadPath = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseKVSecret validates KV v1 and v2 response parsing
+func TestParseKVSecret(t *testing.T) {
+	v2 := &vault.Secret{Data: map[string]interface{}{
+		"data":     map[string]interface{}{"prodDomain": "example.com"},
+		"metadata": map[string]interface{}{"version": json.Number("3")},
+	}}
+	v1 := &vault.Secret{Data: map[string]interface{}{"prodDomain": "example.com"}}
+
+	tests := []struct {
+		name        string
+		secret      *vault.Secret
+		version     int
+		wantVersion int64
+		wantError   bool
+	}{
+		{name: "v2 mount", secret: v2, version: 2, wantVersion: 3},
+		{name: "v2 inferred", secret: v2, version: 0, wantVersion: 3},
+		{name: "v1 mount", secret: v1, version: 1},
+		{name: "v1 inferred", secret: v1, version: 0},
+		{name: "v1 data on v2 mount", secret: v1, version: 2, wantError: true},
+		{name: "missing secret", secret: nil, version: 2, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kv, err := parseKVSecret("kv/domains", tt.secret, tt.version)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if kv.Data["prodDomain"] != "example.com" {
+				t.Errorf("Data[prodDomain] = %v, want example.com", kv.Data["prodDomain"])
+			}
+			if kv.Version != tt.wantVersion {
+				t.Errorf("Version = %v, want %v", kv.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+// TestLookupKVMount validates parsing of sys/internal/ui/mounts responses
+func TestLookupKVMount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sys/internal/ui/mounts/kv/domains" {
+			t.Errorf("mount lookup path = %v", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":{"path":"kv/","type":"kv","options":{"version":"2"}}}`))
+	}))
+	defer server.Close()
+
+	config := vault.DefaultConfig()
+	config.Address = server.URL
+	client, err := vault.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mount, err := lookupKVMount(context.Background(), client, "kv/domains")
+	if err != nil {
+		t.Fatalf("lookupKVMount returned error: %v", err)
+	}
+	if mount.Path != "kv/" || mount.Version != 2 {
+		t.Errorf("mount = %+v, want kv/ version 2", mount)
+	}
+}
+
+// TestNewVaultClient validates that connection clients ignore the environment's token and namespace
+func TestNewVaultClient(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "env-token")
+	t.Setenv("VAULT_NAMESPACE", "env-namespace")
+
+	client, err := NewVaultClient(context.Background(), VaultConnectionConfig{
+		Address:   "https://vault.lab.example.com:8200",
+		Namespace: "homelab",
+		Token:     "s.connection",
+		Auth:      VaultAuthConfig{Method: VaultAuthToken},
+	})
+	if err != nil {
+		t.Fatalf("NewVaultClient returned error: %v", err)
+	}
+	if client.Address() != "https://vault.lab.example.com:8200" {
+		t.Errorf("Address = %v, want https://vault.lab.example.com:8200", client.Address())
+	}
+	if client.Token() != "s.connection" {
+		t.Errorf("Token = %v, want s.connection", client.Token())
+	}
+	if client.Namespace() != "homelab" {
+		t.Errorf("Namespace = %v, want homelab", client.Namespace())
+	}
+
+	if _, err := NewVaultClient(context.Background(), VaultConnectionConfig{
+		Address: "https://vault.lab.example.com:8200",
+		Auth:    VaultAuthConfig{Method: VaultAuthToken},
+	}); err == nil {
+		t.Error("Expected error for token auth without a token")
+	}
+
+	if _, err := NewVaultClient(context.Background(), VaultConnectionConfig{
+		Address:  "https://vault.lab.example.com:8200",
+		CABundle: "not a certificate",
+		Auth:     VaultAuthConfig{Method: VaultAuthToken},
+	}); err == nil {
+		t.Error("Expected error for an invalid CA bundle")
+	}
+}